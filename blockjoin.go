@@ -0,0 +1,43 @@
+package solr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChildQuery returns a block join query matching the children of the parent documents
+// that match the given child query, which despite its name is a query on parents.
+// The parentFilter must match all the parent documents of the index, e.g.
+// `content_type:order`. The result can be used with `SetQuery` or `AddFilter`.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/other-parsers.html#block-join-children-query-parser
+func ChildQuery(parentFilter, childQuery string) string {
+	return fmt.Sprintf("{!child of=%s}%s", formatLocalParamValue(parentFilter), childQuery)
+}
+
+// ParentQuery returns a block join query matching the parent documents of the children
+// that match the given child query. The parentFilter must match all the parent
+// documents of the index, e.g. `content_type:order`. The result can be used
+// with `SetQuery` or `AddFilter`.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/other-parsers.html#block-join-parent-query-parser
+func ParentQuery(parentFilter, childQuery string) string {
+	return fmt.Sprintf("{!parent which=%s}%s", formatLocalParamValue(parentFilter), childQuery)
+}
+
+// ChildTransformer returns the child doc transformer, which can be added to the field
+// list with `AddField` in order to return the children of each parent document
+// along with it. The childFilter is optional and restricts the returned
+// children, while limit is omitted when not greater than zero.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/transforming-result-documents.html#child-childdoctransformerfactory
+func ChildTransformer(parentFilter, childFilter string, limit int) string {
+	params := []string{"child", "parentFilter=" + formatLocalParamValue(parentFilter)}
+	if childFilter != "" {
+		params = append(params, "childFilter="+formatLocalParamValue(childFilter))
+	}
+	if limit > 0 {
+		params = append(params, fmt.Sprintf("limit=%d", limit))
+	}
+	return "[" + strings.Join(params, " ") + "]"
+}
@@ -0,0 +1,33 @@
+package solr
+
+import "testing"
+
+func TestChildQuery(t *testing.T) {
+	actual := ChildQuery("content_type:order", "customer:alice")
+	expected := `{!child of=content_type:order}customer:alice`
+	if actual != expected {
+		t.Fatalf("expected %s but got %s", expected, actual)
+	}
+}
+
+func TestParentQuery(t *testing.T) {
+	actual := ParentQuery(`type:"sales order"`, "sku:123")
+	expected := `{!parent which='type:"sales order"'}sku:123`
+	if actual != expected {
+		t.Fatalf("expected %s but got %s", expected, actual)
+	}
+}
+
+func TestChildTransformer(t *testing.T) {
+	actual := ChildTransformer("content_type:order", "", 0)
+	expected := `[child parentFilter=content_type:order]`
+	if actual != expected {
+		t.Fatalf("expected %s but got %s", expected, actual)
+	}
+
+	actual = ChildTransformer("content_type:order", "name:it's", 5)
+	expected = `[child parentFilter=content_type:order childFilter='name:it\'s' limit=5]`
+	if actual != expected {
+		t.Fatalf("expected %s but got %s", expected, actual)
+	}
+}
@@ -3,6 +3,7 @@ package solr
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
@@ -13,6 +14,7 @@ import (
 type SingleClient struct {
 	conn     connection
 	BasePath string
+	pingPath string
 }
 
 // NewSingleClient returns a connection to the solr client provided by the given
@@ -27,17 +29,24 @@ func (c *SingleClient) SetBasicAuth(username, password string) {
 	c.conn.setBasicAuth(username, password)
 }
 
+// SetKeepRaw sets whether the raw response body should be kept.
+func (c *SingleClient) SetKeepRaw(keep bool) {
+	c.conn.setKeepRaw(keep)
+}
+
+// SetPingPath sets the path of the ping request handler.
+func (c *SingleClient) SetPingPath(path string) {
+	c.pingPath = path
+}
+
 func (c *SingleClient) formatURL(path string, query string) string {
-	if query != "" {
-		return c.BasePath + path + "?" + query
-	}
-	return c.BasePath + path
+	return formatURL(c.BasePath, path, query)
 }
 
 // Ping ...
 func (c *SingleClient) Ping(ctx context.Context) error {
-	url := c.formatURL("/admin/ping", "")
-	res, err := c.conn.request(ctx, http.MethodGet, url, nil)
+	url := c.formatURL(pingPath(c.pingPath), "")
+	res, err := c.conn.request(ctx, http.MethodGet, url, ContentTypeJSON, nil)
 	if err != nil {
 		return err
 	}
@@ -53,6 +62,33 @@ func (c *SingleClient) Search(ctx context.Context, q *Query) (*Response, error)
 	return read(ctx, c.conn, url)
 }
 
+// SearchHandler performs the query against the provided request handler instead of `/select`.
+// The handler is prefixed with a slash if needed, so both `browse` and `/browse` are accepted.
+func (c *SingleClient) SearchHandler(ctx context.Context, handler string, q *Query) (*Response, error) {
+	url := c.formatURL(formatHandlerPath(handler), q.String())
+	return read(ctx, c.conn, url)
+}
+
+// Suggest ...
+func (c *SingleClient) Suggest(ctx context.Context, params *SuggestParams) (*Response, error) {
+	vals, err := params.format()
+	if err != nil {
+		return nil, err
+	}
+	url := c.formatURL(params.handlerPath(), vals.Encode())
+	return read(ctx, c.conn, url)
+}
+
+// Terms ...
+func (c *SingleClient) Terms(ctx context.Context, params *TermsParams) (*Response, error) {
+	vals, err := params.format()
+	if err != nil {
+		return nil, err
+	}
+	url := c.formatURL(params.handlerPath(), vals.Encode())
+	return read(ctx, c.conn, url)
+}
+
 // Get ...
 func (c *SingleClient) Get(ctx context.Context, id, filter string) (*Response, error) {
 	vals := make(url.Values)
@@ -75,22 +111,61 @@ func (c *SingleClient) BatchGet(ctx context.Context, ids []string, filter string
 	return read(ctx, c.conn, url)
 }
 
+// MaxVersion returns the highest absolute `_version_` found in the update log, as deletions are
+// logged with a negative version. ErrNoVersions is returned when the update log is empty.
+func (c *SingleClient) MaxVersion(ctx context.Context) (int64, error) {
+	url := c.formatURL("/get", "getVersions=1")
+	return maxVersion(ctx, c.conn, url)
+}
+
 // Create ...
 func (c *SingleClient) Create(ctx context.Context, item interface{}, opts *WriteOptions) (*Response, error) {
-	url := c.formatURL("/update/json/docs", opts.formatQueryFromOpts().Encode())
-	return create(ctx, c.conn, url, item)
+	url := c.formatURL(opts.handlerPath("/update/json/docs"), opts.formatQueryFromOpts().Encode())
+	return create(ctx, c.conn, url, item, opts)
 }
 
 // BatchCreate ...
 func (c *SingleClient) BatchCreate(ctx context.Context, items interface{}, opts *WriteOptions) (*Response, error) {
-	url := c.formatURL("/update", opts.formatQueryFromOpts().Encode())
-	return batchCreate(ctx, c.conn, url, items)
+	url := c.formatURL(opts.handlerPath("/update"), opts.formatQueryFromOpts().Encode())
+	return batchCreate(ctx, c.conn, url, items, opts)
+}
+
+// BatchCreateStream ...
+func (c *SingleClient) BatchCreateStream(ctx context.Context, r io.Reader, opts *WriteOptions) (*Response, error) {
+	url := c.formatURL(opts.handlerPath("/update/json/docs"), opts.formatQueryFromOpts().Encode())
+	return batchCreateStream(ctx, c.conn, url, r)
+}
+
+// UploadCSV ...
+func (c *SingleClient) UploadCSV(ctx context.Context, data []byte, opts *WriteOptions) (*Response, error) {
+	url := c.formatURL(opts.handlerPath("/update"), opts.formatQueryFromOpts().Encode())
+	return upload(ctx, c.conn, url, ContentTypeCSV, data)
+}
+
+// UploadXML ...
+func (c *SingleClient) UploadXML(ctx context.Context, data []byte, opts *WriteOptions) (*Response, error) {
+	url := c.formatURL(opts.handlerPath("/update"), opts.formatQueryFromOpts().Encode())
+	return upload(ctx, c.conn, url, ContentTypeXML, data)
+}
+
+// ExtractAndIndex ...
+func (c *SingleClient) ExtractAndIndex(ctx context.Context, file io.Reader, contentType string, literals map[string]string, opts *ExtractOptions) (*Response, error) {
+	url := c.formatURL(opts.writeOptions().handlerPath("/update/extract"), formatExtractQuery(literals, opts).Encode())
+	return extract(ctx, c.conn, url, contentType, file)
 }
 
 // Update ...
 func (c *SingleClient) Update(ctx context.Context, item *UpdatedFields, opts *WriteOptions) (*Response, error) {
-	url := c.formatURL("/update", opts.formatQueryFromOpts().Encode())
-	return update(ctx, c.conn, url, item)
+	url := c.formatURL(opts.handlerPath("/update"), opts.formatQueryFromOpts().Encode())
+	return update(ctx, c.conn, url, item, opts)
+}
+
+// Upsert sets every provided field on the document with the given id via atomic `set`
+// updates, creating the document if it does not exist. A `_version_` entry in the fields
+// is passed through as is instead of being wrapped in a `set`, enabling optimistic
+// concurrency (check `UpdatedFields.SetVersion`).
+func (c *SingleClient) Upsert(ctx context.Context, id string, fields map[string]interface{}, opts *WriteOptions) (*Response, error) {
+	return c.Update(ctx, newUpsertDocument(id, fields), opts)
 }
 
 // Commit ...
@@ -113,13 +188,21 @@ func (c *SingleClient) Optimize(ctx context.Context, opts *OptimizeOptions) (*Re
 
 // DeleteByID ...
 func (c *SingleClient) DeleteByID(ctx context.Context, id string, opts *WriteOptions) (*Response, error) {
-	url := c.formatURL("/update", opts.formatQueryFromOpts().Encode())
+	url := c.formatURL(opts.handlerPath("/update"), opts.formatQueryFromOpts().Encode())
 	return delete(ctx, c.conn, url, formatDeleteByID(id))
 }
 
+// DeleteByIDs ...
+func (c *SingleClient) DeleteByIDs(ctx context.Context, ids []string, opts *WriteOptions) (*Response, error) {
+	path := opts.handlerPath("/update")
+	chunkURL := c.formatURL(path, opts.formatChunkQueryFromOpts().Encode())
+	lastURL := c.formatURL(path, opts.formatQueryFromOpts().Encode())
+	return deleteByIDs(ctx, c.conn, chunkURL, lastURL, ids, opts.chunkSize())
+}
+
 // DeleteByQuery ...
 func (c *SingleClient) DeleteByQuery(ctx context.Context, query string, opts *WriteOptions) (*Response, error) {
-	url := c.formatURL("/update", opts.formatQueryFromOpts().Encode())
+	url := c.formatURL(opts.handlerPath("/update"), opts.formatQueryFromOpts().Encode())
 	return delete(ctx, c.conn, url, formatDeleteByQuery(query))
 }
 
@@ -130,6 +213,16 @@ func (c *SingleClient) Clear(ctx context.Context) (*Response, error) {
 
 // CustomUpdate ...
 func (c *SingleClient) CustomUpdate(ctx context.Context, item *UpdateBuilder, opts *WriteOptions) (*Response, error) {
-	url := c.formatURL("/update", opts.formatQueryFromOpts().Encode())
+	url := c.formatURL(opts.handlerPath("/update"), opts.formatQueryFromOpts().Encode())
 	return customUpdate(ctx, c.conn, url, item)
 }
+
+// DataImport ...
+func (c *SingleClient) DataImport(ctx context.Context, handler string, command DataImportCommand, params map[string]string) (*Response, error) {
+	query, err := formatDataImportQuery(command, params)
+	if err != nil {
+		return nil, err
+	}
+	url := c.formatURL(formatHandlerPath(handler), query)
+	return read(ctx, c.conn, url)
+}
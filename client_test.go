@@ -0,0 +1,483 @@
+package solr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mecenat/solr/solrtest"
+)
+
+func newTestClient(t *testing.T, srv *solrtest.Server) Client {
+	t.Helper()
+	conn, err := NewConnection(srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewSingleClient(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestCreateWriteOptions(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	c := newTestClient(t, srv)
+
+	opts := &WriteOptions{CommitWithin: 1000, AllowDuplicate: true}
+	_, err := c.Create(context.Background(), map[string]interface{}{"id": "1"}, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req := srv.LastRequest()
+	if req.Method != http.MethodPost || req.Path != "/solr/films/update/json/docs" {
+		t.Fatalf("unexpected request: %s %s", req.Method, req.Path)
+	}
+	solrtest.AssertParam(t, req, "commitWithin", "1000")
+	solrtest.AssertParam(t, req, "overwrite", "false")
+	solrtest.AssertJSONBody(t, req, `{"id":"1"}`)
+
+	_, err = c.BatchCreate(context.Background(), []map[string]interface{}{{"id": "1"}, {"id": "2"}}, &WriteOptions{Commit: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req = srv.LastRequest()
+	solrtest.AssertParam(t, req, "commit", "true")
+	solrtest.AssertParam(t, req, "commitWithin", "")
+}
+
+func TestDeleteByIDs(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	c := newTestClient(t, srv)
+
+	_, err := c.DeleteByIDs(context.Background(), nil, nil)
+	if err != ErrNoIDs {
+		t.Fatalf("expected %v but got %v", ErrNoIDs, err)
+	}
+
+	_, err = c.DeleteByIDs(context.Background(), []string{"1", "2", "3"}, &WriteOptions{Commit: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req := srv.LastRequest()
+	if req.Path != "/solr/films/update" {
+		t.Fatalf("unexpected path: %s", req.Path)
+	}
+	solrtest.AssertParam(t, req, "commit", "true")
+	solrtest.AssertJSONBody(t, req, `{"delete":["1","2","3"]}`)
+
+	srv.Reset()
+	_, err = c.DeleteByIDs(context.Background(), []string{"1", "2", "3", "4", "5"}, &WriteOptions{ChunkSize: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reqs := srv.Requests()
+	if len(reqs) != 3 {
+		t.Fatalf("expected 3 requests but got %d", len(reqs))
+	}
+	solrtest.AssertJSONBody(t, reqs[0], `{"delete":["1","2"]}`)
+	solrtest.AssertJSONBody(t, reqs[1], `{"delete":["3","4"]}`)
+	solrtest.AssertJSONBody(t, reqs[2], `{"delete":["5"]}`)
+
+	srv.Reset()
+	_, err = c.DeleteByIDs(context.Background(), []string{"1", "2", "3"}, &WriteOptions{ChunkSize: 2, Commit: true, CommitWithin: 100})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reqs = srv.Requests()
+	if len(reqs) != 2 {
+		t.Fatalf("expected 2 requests but got %d", len(reqs))
+	}
+	if reqs[0].Query.Get("commit") != "" || reqs[0].Query.Get("commitWithin") != "" {
+		t.Fatalf("expected no commit options on the first chunk but got %s", reqs[0].Query.Encode())
+	}
+	solrtest.AssertParam(t, reqs[1], "commit", "true")
+	solrtest.AssertParam(t, reqs[1], "commitWithin", "100")
+}
+
+func TestDeleteByIDsPartialFailure(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"responseHeader":{"status":500},"error":{"msg":"boom","code":500}}`))
+			return
+		}
+		w.Write([]byte(solrtest.DefaultResponse))
+	}))
+	defer srv.Close()
+
+	conn, err := NewConnection(srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewSingleClient(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.DeleteByIDs(context.Background(), []string{"1", "2", "3", "4", "5"}, &WriteOptions{ChunkSize: 2})
+	var partialErr *PartialDeleteError
+	if !errors.As(err, &partialErr) {
+		t.Fatalf("expected a PartialDeleteError but got %v", err)
+	}
+	if partialErr.Deleted != 4 {
+		t.Fatalf("expected 4 deleted ids but got %d", partialErr.Deleted)
+	}
+	var resErr *ResponseError
+	if !errors.As(err, &resErr) {
+		t.Fatalf("expected the underlying ResponseError but got %v", partialErr.Err)
+	}
+}
+
+func TestDeleteByIDsAffected(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Write([]byte(`{"responseHeader":{"status":0},"deletes":["1",-1680859137037729792,"2",-1680859137037729793]}`))
+			return
+		}
+		w.Write([]byte(`{"responseHeader":{"status":0},"deletes":["3",-1680859137037729794]}`))
+	}))
+	defer srv.Close()
+
+	conn, err := NewConnection(srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewSingleClient(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := c.DeleteByIDs(context.Background(), []string{"1", "2", "3"}, &WriteOptions{ChunkSize: 2, ReturnVersions: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Affected() != 3 {
+		t.Fatalf("expected the deletes of every chunk to be counted but got %d", res.Affected())
+	}
+	if res.Deletes[0].ID != "1" || res.Deletes[2].ID != "3" {
+		t.Fatalf("unexpected deletes: %+v", res.Deletes)
+	}
+}
+
+func TestBatchCreateStream(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+
+	conn, err := NewConnection(srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	prc, err := NewPrimaryReplicaClient(conn, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := "{\"id\":\"1\"}\n{\"id\":\"2\"}\n"
+	for _, c := range []Client{newTestClient(t, srv), prc} {
+		srv.Reset()
+		_, err := c.BatchCreateStream(context.Background(), strings.NewReader(body), &WriteOptions{Commit: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		req := srv.LastRequest()
+		if req.Method != http.MethodPost || req.Path != "/solr/films/update/json/docs" {
+			t.Fatalf("unexpected request: %s %s", req.Method, req.Path)
+		}
+		if req.ContentType != ContentTypeJSON {
+			t.Fatalf("unexpected content type: %s", req.ContentType)
+		}
+		solrtest.AssertParam(t, req, "commit", "true")
+		if string(req.Body) != body {
+			t.Fatalf("unexpected body: %s", req.Body)
+		}
+	}
+}
+
+func TestUploadCSVAndXML(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+
+	conn, err := NewConnection(srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	prc, err := NewPrimaryReplicaClient(conn, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	csv := "id,name\n1,Alien\n"
+	xml := "<add><doc><field name=\"id\">1</field></doc></add>"
+	for _, c := range []Client{newTestClient(t, srv), prc} {
+		srv.Reset()
+		_, err := c.UploadCSV(context.Background(), []byte(csv), &WriteOptions{Commit: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		req := srv.LastRequest()
+		if req.Method != http.MethodPost || req.Path != "/solr/films/update" {
+			t.Fatalf("unexpected request: %s %s", req.Method, req.Path)
+		}
+		if req.ContentType != ContentTypeCSV {
+			t.Fatalf("unexpected content type: %s", req.ContentType)
+		}
+		solrtest.AssertParam(t, req, "commit", "true")
+		if string(req.Body) != csv {
+			t.Fatalf("unexpected body: %s", req.Body)
+		}
+
+		_, err = c.UploadXML(context.Background(), []byte(xml), nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		req = srv.LastRequest()
+		if req.Method != http.MethodPost || req.Path != "/solr/films/update" {
+			t.Fatalf("unexpected request: %s %s", req.Method, req.Path)
+		}
+		if req.ContentType != ContentTypeXML {
+			t.Fatalf("unexpected content type: %s", req.ContentType)
+		}
+		if string(req.Body) != xml {
+			t.Fatalf("unexpected body: %s", req.Body)
+		}
+	}
+}
+
+func TestMultiReplicaClient(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+
+	var replicas []connection
+	for _, core := range []string{"a", "b", "c"} {
+		conn, err := NewConnection(srv.URL, core, srv.Client())
+		if err != nil {
+			t.Fatal(err)
+		}
+		replicas = append(replicas, conn)
+	}
+	primary, err := NewConnection(srv.URL, "primary", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewMultiReplicaClient(primary)
+	if err != ErrNoReplicas {
+		t.Fatalf("expected %v but got %v", ErrNoReplicas, err)
+	}
+
+	c, err := NewMultiReplicaClient(primary, replicas...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		_, err = c.Search(context.Background(), NewQuery(nil))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	reqs := srv.Requests()
+	for i, path := range []string{"/solr/a/select", "/solr/b/select", "/solr/c/select", "/solr/a/select"} {
+		if reqs[i].Path != path {
+			t.Fatalf("expected request %d to %s but got %s", i, path, reqs[i].Path)
+		}
+	}
+
+	srv.Reset()
+	_, err = c.Create(context.Background(), map[string]interface{}{"id": "1"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path := srv.LastRequest().Path; path != "/solr/primary/update/json/docs" {
+		t.Fatalf("expected the write to go to the primary but got %s", path)
+	}
+
+	srv.Reset()
+	srv.Handle("/solr/b/select", http.StatusServiceUnavailable, "unavailable")
+	_, err = c.Search(context.Background(), NewQuery(nil))
+	if err != nil {
+		t.Fatalf("expected to fail over but got: %v", err)
+	}
+	reqs = srv.Requests()
+	if len(reqs) != 2 || reqs[1].Path != "/solr/c/select" {
+		t.Fatalf("expected to fail over to the next replica but got %d requests", len(reqs))
+	}
+
+	srv.Reset()
+	srv.Handle("/solr/c/select", http.StatusBadRequest, `{"error":{"code":400,"msg":"undefined field foo"}}`)
+	res, err := c.Search(context.Background(), NewQuery(nil))
+	var resErr *ResponseError
+	if !errors.As(err, &resErr) {
+		t.Fatalf("expected the solr error to be returned but got %v", err)
+	}
+	if res == nil || res.Error == nil || res.Error.Code != 400 {
+		t.Fatalf("expected the parsed response along with the error but got %+v", res)
+	}
+	if len(srv.Requests()) != 1 {
+		t.Fatalf("expected no fail over on solr errors but got %d requests", len(srv.Requests()))
+	}
+
+	srv.Reset()
+	for _, path := range []string{"/solr/a/select", "/solr/b/select", "/solr/c/select"} {
+		srv.Handle(path, http.StatusServiceUnavailable, `{"responseHeader":{"status":503,"QTime":0}}`)
+	}
+	res, err = c.Search(context.Background(), NewQuery(nil))
+	if err == nil {
+		t.Fatal("expected an error when all replicas fail")
+	}
+	if res == nil || res.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected the last response along with the error but got %+v", res)
+	}
+	if len(srv.Requests()) != 3 {
+		t.Fatalf("expected every replica to be tried but got %d requests", len(srv.Requests()))
+	}
+}
+
+func TestPrimaryReplicaClientResponseError(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+
+	conn, err := NewConnection(srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	prc, err := NewPrimaryReplicaClient(conn, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv.Handle("/solr/films/select", http.StatusBadRequest, `{"responseHeader":{"status":400,"QTime":1},"error":{"code":400,"msg":"undefined field foo"}}`)
+	res, err := prc.Search(context.Background(), NewQuery(nil))
+	var resErr *ResponseError
+	if !errors.As(err, &resErr) {
+		t.Fatalf("expected a ResponseError but got %v", err)
+	}
+	if res == nil || res.Header == nil || res.Header.Status != 400 {
+		t.Fatalf("expected the parsed response along with the error but got %+v", res)
+	}
+}
+
+func TestSetPingPath(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	c := newTestClient(t, srv)
+
+	err := c.Ping(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path := srv.LastRequest().Path; path != "/solr/films/admin/ping" {
+		t.Fatalf("expected the default ping path but got %s", path)
+	}
+
+	c.SetPingPath("health")
+	err = c.Ping(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path := srv.LastRequest().Path; path != "/solr/films/health" {
+		t.Fatalf("expected the custom ping path but got %s", path)
+	}
+
+	conn, err := NewConnection(srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	prc, err := NewPrimaryReplicaClient(conn, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Reset()
+	prc.SetPingPath("/health")
+	err = prc.Ping(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, req := range srv.Requests() {
+		if req.Path != "/solr/films/health" {
+			t.Fatalf("expected the custom ping path but got %s", req.Path)
+		}
+	}
+}
+
+func TestMaxVersion(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	c := newTestClient(t, srv)
+
+	srv.Handle("/solr/films/get", http.StatusOK, `{"versions":[1612345678901234567,-1612345678901234999,1612345678901234000]}`)
+	max, err := c.MaxVersion(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if max != 1612345678901234999 {
+		t.Fatalf("expected the absolute max version but got %d", max)
+	}
+	req := srv.LastRequest()
+	if req.Method != http.MethodGet || req.Path != "/solr/films/get" {
+		t.Fatalf("unexpected request: %s %s", req.Method, req.Path)
+	}
+	solrtest.AssertParam(t, req, "getVersions", "1")
+
+	srv.Handle("/solr/films/get", http.StatusOK, `{"versions":[]}`)
+	_, err = c.MaxVersion(context.Background())
+	if err != ErrNoVersions {
+		t.Fatalf("expected %v but got %v", ErrNoVersions, err)
+	}
+}
+
+func TestSearchHandler(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+
+	conn, err := NewConnection(srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	prc, err := NewPrimaryReplicaClient(conn, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []Client{newTestClient(t, srv), prc} {
+		for _, handler := range []string{"browse", "/browse"} {
+			_, err := c.SearchHandler(context.Background(), handler, NewQuery(&ReadOptions{Rows: 5}))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			req := srv.LastRequest()
+			if req.Method != http.MethodGet || req.Path != "/solr/films/browse" {
+				t.Fatalf("unexpected request: %s %s", req.Method, req.Path)
+			}
+			solrtest.AssertParam(t, req, "rows", "5")
+		}
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	c := newTestClient(t, srv)
+
+	fields := map[string]interface{}{"id": "other", "name": "Alien", "_version_": 1}
+	_, err := c.Upsert(context.Background(), "1", fields, &WriteOptions{Commit: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req := srv.LastRequest()
+	if req.Method != http.MethodPost || req.Path != "/solr/films/update" {
+		t.Fatalf("unexpected request: %s %s", req.Method, req.Path)
+	}
+	solrtest.AssertParam(t, req, "commit", "true")
+	solrtest.AssertJSONBody(t, req, `{"add":{"doc":{"id":"1","name":{"set":"Alien"},"_version_":1}}}`)
+}
@@ -13,10 +13,43 @@ import (
 	"github.com/hashicorp/go-retryablehttp"
 )
 
+// Content types that can be used for the body of a request to solr
+const (
+	ContentTypeJSON = "application/json"
+	ContentTypeCSV  = "application/csv"
+	ContentTypeXML  = "application/xml"
+	ContentTypeForm = "application/x-www-form-urlencoded"
+)
+
+type basicAuthKey struct{}
+
+type basicAuth struct {
+	username string
+	password string
+}
+
+// WithBasicAuth returns a copy of the context carrying the given credentials, which
+// override the ones set on the connection for any request made with it. This
+// allows acting as different users without creating a client for each one.
+func WithBasicAuth(ctx context.Context, username, password string) context.Context {
+	return context.WithValue(ctx, basicAuthKey{}, &basicAuth{username: username, password: password})
+}
+
+// credentials returns the credentials carried by the context, if any, or else
+// the given default ones.
+func credentials(ctx context.Context, username, password string) (string, string) {
+	if auth, ok := ctx.Value(basicAuthKey{}).(*basicAuth); ok {
+		return auth.username, auth.password
+	}
+	return username, password
+}
+
 type connection interface {
-	request(ctx context.Context, method, path string, body []byte) (*Response, error)
+	request(ctx context.Context, method, path, contentType string, body []byte) (*Response, error)
+	stream(ctx context.Context, method, path, contentType string, body io.Reader) (*Response, error)
 	formatBasePath() string
 	setBasicAuth(username, password string)
+	setKeepRaw(keep bool)
 }
 
 // Connection represents the connection to the solr server and
@@ -28,6 +61,45 @@ type Connection struct {
 	Core       string
 	Username   string
 	Password   string
+	// UseNumber decodes the numbers found in untyped values of the responses
+	// (e.g. document fields) as json.Number instead of float64, in order to
+	// preserve the precision of large integers such as `_version_`. It does
+	// not apply to the sections that are decoded separately, namely the
+	// documents of grouped results (`Grouped`) and the bucket values of
+	// JSON facets (`JSONFacetResult`), which are still decoded as float64.
+	// Those may be decoded from `Response.Raw` when precision matters.
+	UseNumber bool
+	// KeepRaw stores the raw body of the responses in `Response.Raw`.
+	KeepRaw bool
+	// OnRequest, if set, is called after each request with information about
+	// it, e.g. in order to record metrics or tracing spans. The CoreAdmin,
+	// SchemaAPI & ManagedAPI create their own connections, therefore their
+	// hook is set with their `SetOnRequest` method.
+	OnRequest func(info RequestInfo)
+}
+
+// RequestInfo contains information about a request made to the solr server,
+// passed to the OnRequest hook of the connections. StatusCode is zero when
+// no response was received, while Err is the error returned (if any).
+type RequestInfo struct {
+	Method     string
+	URL        string
+	Duration   time.Duration
+	StatusCode int
+	Err        error
+}
+
+func notifyRequest(hook func(info RequestInfo), method, url string, start time.Time, statusCode int, err error) {
+	if hook == nil {
+		return
+	}
+	hook(RequestInfo{
+		Method:     method,
+		URL:        url,
+		Duration:   time.Since(start),
+		StatusCode: statusCode,
+		Err:        err,
+	})
 }
 
 // NewConnection ...
@@ -57,36 +129,39 @@ func (c *Connection) setBasicAuth(username, password string) {
 	c.Password = password
 }
 
-func (c *Connection) request(ctx context.Context, method, url string, body []byte) (*Response, error) {
-	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+func (c *Connection) setKeepRaw(keep bool) {
+	c.KeepRaw = keep
+}
+
+func (c *Connection) request(ctx context.Context, method, url, contentType string, body []byte) (*Response, error) {
+	return c.stream(ctx, method, url, contentType, bytes.NewReader(body))
+}
+
+// stream sends the request reading the body from the provided reader,
+// without loading it in memory.
+func (c *Connection) stream(ctx context.Context, method, url, contentType string, body io.Reader) (*Response, error) {
+	req, err := http.NewRequest(method, url, body)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Content-Type", contentType)
 
-	if c.Username != "" && c.Password != "" {
-		req.SetBasicAuth(c.Username, c.Password)
+	if username, password := credentials(ctx, c.Username, c.Password); username != "" && password != "" {
+		req.SetBasicAuth(username, password)
 	}
 
+	start := time.Now()
 	res, err := c.httpClient.Do(req.WithContext(ctx))
 	if err != nil {
+		notifyRequest(c.OnRequest, method, url, start, 0, err)
 		return nil, err
 	}
 
-	var r Response
 	defer res.Body.Close()
-
-	err = json.NewDecoder(res.Body).Decode(&r)
-	if err != nil {
-		return nil, err
-	}
-
-	if r.Error != nil {
-		return &r, r.Error
-	}
-
-	return &r, nil
+	r, err := decodeResponse(res.StatusCode, res.Body, c.UseNumber, c.KeepRaw)
+	notifyRequest(c.OnRequest, method, url, start, res.StatusCode, err)
+	return r, err
 }
 
 // RetryableConnection implements the retryablehttp library from Hashicorp that allows
@@ -94,22 +169,42 @@ func (c *Connection) request(ctx context.Context, method, url string, body []byt
 // connectivity issues. This for example can be useful if your solr servers are
 // being shutdown while a new one gets started, the request can continue
 // trying allowing for the server to be replaced without dropping it.
+// UseNumber, KeepRaw & OnRequest behave the same as in the simple Connection,
+// with the duration passed to OnRequest including all the attempts.
 type RetryableConnection struct {
 	Host        string
 	Core        string
 	Username    string
 	Password    string
 	Timeout     time.Duration
+	UseNumber   bool
+	KeepRaw     bool
+	OnRequest   func(info RequestInfo)
 	httpClient  *http.Client
 	retryClient *retryablehttp.Client
 }
 
+// DefaultRetryableStatusCodes are the status codes that are retried when none
+// are provided in the RetryableConfig, which solr returns e.g. while the
+// nodes hosting a shard are being restarted.
+var DefaultRetryableStatusCodes = []int{
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryableConfig contains the configuration of a RetryableConnection. Besides
+// transport errors, the requests are retried when solr responds with one of
+// the RetryableStatusCodes (DefaultRetryableStatusCodes if empty). Timeout
+// applies to each attempt, while the deadline of the context applies to
+// the request as a whole: no more attempts are made once it is done.
 type RetryableConfig struct {
-	Timeout      time.Duration
-	RetryWaitMin time.Duration
-	RetryWaitMax time.Duration
-	RetryMax     int
-	NoLog        bool
+	Timeout              time.Duration
+	RetryWaitMin         time.Duration
+	RetryWaitMax         time.Duration
+	RetryMax             int
+	NoLog                bool
+	RetryableStatusCodes []int
 }
 
 // NewRetryableConnection ...
@@ -139,6 +234,8 @@ func NewRetryableConnection(host, core string, client *http.Client, conf *Retrya
 	retryClient.RetryWaitMin = conf.RetryWaitMin
 	retryClient.RetryWaitMax = conf.RetryWaitMax
 	retryClient.RetryMax = conf.RetryMax
+	retryClient.CheckRetry = retryPolicy(conf.RetryableStatusCodes)
+	retryClient.ErrorHandler = retryablehttp.PassthroughErrorHandler
 	if conf.NoLog {
 		retryClient.Logger = log.New(io.Discard, "", log.LstdFlags)
 	}
@@ -152,6 +249,29 @@ func NewRetryableConnection(host, core string, client *http.Client, conf *Retrya
 	}, nil
 }
 
+// retryPolicy returns a retryablehttp.CheckRetry that retries on transport errors
+// and on the provided status codes. When the retries are exhausted the last
+// response is decoded as usual, returning the error of solr.
+func retryPolicy(statusCodes []int) retryablehttp.CheckRetry {
+	if len(statusCodes) == 0 {
+		statusCodes = DefaultRetryableStatusCodes
+	}
+	return func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		if err != nil {
+			return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+		}
+		for _, code := range statusCodes {
+			if resp.StatusCode == code {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
 func (c *RetryableConnection) formatBasePath() string {
 	return formatBasePath(c.Host, c.Core)
 }
@@ -161,33 +281,108 @@ func (c *RetryableConnection) setBasicAuth(username, password string) {
 	c.Password = password
 }
 
-func (c *RetryableConnection) request(ctx context.Context, method, path string, body []byte) (*Response, error) {
-	req, err := retryablehttp.NewRequest(method, path, bytes.NewBuffer(body))
+func (c *RetryableConnection) setKeepRaw(keep bool) {
+	c.KeepRaw = keep
+}
+
+func (c *RetryableConnection) request(ctx context.Context, method, path, contentType string, body []byte) (*Response, error) {
+	return c.stream(ctx, method, path, contentType, bytes.NewReader(body))
+}
+
+// stream sends the request reading the body from the provided reader. Since
+// retryablehttp buffers readers that cannot be rewound in order to be able
+// to send the body again when retrying, such readers are sent only once
+// through the underlying http client, without retries.
+func (c *RetryableConnection) stream(ctx context.Context, method, path, contentType string, body io.Reader) (*Response, error) {
+	start := time.Now()
+	res, err := c.do(ctx, method, path, contentType, body)
 	if err != nil {
+		// a response may be returned along with the error, e.g. when the
+		// context is done after it has been received
+		if res != nil {
+			res.Body.Close()
+		}
+		notifyRequest(c.OnRequest, method, path, start, 0, err)
 		return nil, err
 	}
 
-	req.Header.Add("Content-Type", "application/json")
-	if c.Username != "" && c.Password != "" {
-		req.SetBasicAuth(c.Username, c.Password)
+	defer res.Body.Close()
+	r, err := decodeResponse(res.StatusCode, res.Body, c.UseNumber, c.KeepRaw)
+	notifyRequest(c.OnRequest, method, path, start, res.StatusCode, err)
+	return r, err
+}
+
+func (c *RetryableConnection) do(ctx context.Context, method, path, contentType string, body io.Reader) (*http.Response, error) {
+	username, password := credentials(ctx, c.Username, c.Password)
+	if _, ok := body.(io.ReadSeeker); body != nil && !ok {
+		req, err := http.NewRequestWithContext(ctx, method, path, body)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Add("Content-Type", contentType)
+		if username != "" && password != "" {
+			req.SetBasicAuth(username, password)
+		}
+		return c.retryClient.HTTPClient.Do(req)
 	}
 
-	res, err := c.retryClient.Do(req.WithContext(ctx))
+	req, err := retryablehttp.NewRequest(method, path, body)
 	if err != nil {
 		return nil, err
 	}
+	req.Header.Add("Content-Type", contentType)
+	if username != "" && password != "" {
+		req.SetBasicAuth(username, password)
+	}
+	return c.retryClient.Do(req.WithContext(ctx))
+}
+
+// decodeResponse parses the body of a solr response. When useNumber is set
+// the numbers of untyped values are decoded as json.Number, while when
+// keepRaw is set the body is also stored as is in the response. An
+// HTTPError is returned for unsuccessful responses that do not
+// contain a solr error. The status code is stored in the response.
+func decodeResponse(statusCode int, body io.Reader, useNumber, keepRaw bool) (*Response, error) {
+	if statusCode == http.StatusNoContent {
+		return &Response{StatusCode: statusCode}, nil
+	}
+	failed := statusCode >= http.StatusBadRequest
+
+	var raw []byte
+	if keepRaw || failed {
+		b, err := io.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		raw = b
+		body = bytes.NewReader(b)
+	}
 
 	var r Response
-	defer res.Body.Close()
+	dec := json.NewDecoder(body)
+	if useNumber {
+		dec.UseNumber()
+	}
 
-	err = json.NewDecoder(res.Body).Decode(&r)
+	err := dec.Decode(&r)
 	if err != nil {
+		if failed {
+			return nil, &HTTPError{StatusCode: statusCode, Body: string(raw)}
+		}
 		return nil, err
 	}
+	r.StatusCode = statusCode
+	if keepRaw {
+		r.Raw = raw
+	}
 
 	if r.Error != nil {
 		return &r, r.Error
 	}
 
+	if failed {
+		return &r, &HTTPError{StatusCode: statusCode, Body: string(raw)}
+	}
+
 	return &r, nil
 }
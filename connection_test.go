@@ -1,9 +1,16 @@
 package solr
 
 import (
+	"context"
+	"errors"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/mecenat/solr/solrtest"
 )
 
 func TestNewConnection(t *testing.T) {
@@ -67,3 +74,310 @@ func TestNewRetryableConnection(t *testing.T) {
 		t.Fatal("shouldn't get an error but got one")
 	}
 }
+
+func TestKeepRaw(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	body := `{"responseHeader":{"status":0,"QTime":1},"unmodeled":{"answer":42}}`
+	srv.Handle("/solr/mycore/select", http.StatusOK, body)
+
+	conn, err := NewConnection(srv.URL, "mycore", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewSingleClient(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := c.Search(context.Background(), NewQuery(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Raw != nil {
+		t.Fatal("expected no raw body when not requested")
+	}
+
+	c.SetKeepRaw(true)
+	res, err = c.Search(context.Background(), NewQuery(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res.Raw) != body {
+		t.Fatalf("expected raw body %s but got %s", body, res.Raw)
+	}
+	if res.Header.QTime != 1 {
+		t.Fatal("expected the response to still be parsed")
+	}
+}
+
+func TestWithBasicAuth(t *testing.T) {
+	var username, password string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ = r.BasicAuth()
+		w.Write([]byte(solrtest.DefaultResponse))
+	}))
+	defer srv.Close()
+
+	conn, err := NewConnection(srv.URL, "mycore", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewSingleClient(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetBasicAuth("admin", "secret")
+
+	_, err = c.Search(context.Background(), NewQuery(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if username != "admin" || password != "secret" {
+		t.Fatalf("expected the connection credentials but got %s:%s", username, password)
+	}
+
+	ctx := WithBasicAuth(context.Background(), "alice", "wonderland")
+	_, err = c.Search(ctx, NewQuery(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if username != "alice" || password != "wonderland" {
+		t.Fatalf("expected the context credentials but got %s:%s", username, password)
+	}
+}
+
+func TestRetryableStatusCodes(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"responseHeader":{"status":0}}`))
+	}))
+	defer srv.Close()
+
+	conf := &RetryableConfig{RetryWaitMin: time.Millisecond, RetryWaitMax: time.Millisecond, RetryMax: 4, NoLog: true}
+	c, err := NewRetryableConnection(srv.URL, "films", srv.Client(), conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.request(context.Background(), http.MethodGet, srv.URL, ContentTypeJSON, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts but got %d", attempts)
+	}
+
+	attempts = 0
+	conf.RetryableStatusCodes = []int{http.StatusBadGateway}
+	c, err = NewRetryableConnection(srv.URL, "films", srv.Client(), conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.request(context.Background(), http.MethodGet, srv.URL, ContentTypeJSON, nil)
+	httpErr, ok := err.(*HTTPError)
+	if !ok || httpErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected a 503 HTTPError but got %v", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("expected 1 attempt but got %d", attempts)
+	}
+}
+
+func TestRetryableStreamNonSeekable(t *testing.T) {
+	attempts := 0
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	conf := &RetryableConfig{RetryWaitMin: time.Millisecond, RetryWaitMax: time.Millisecond, RetryMax: 4, NoLog: true}
+	c, err := NewRetryableConnection(srv.URL, "films", srv.Client(), conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := io.MultiReader(strings.NewReader(`{"id":"1"}`))
+	_, err = c.stream(context.Background(), http.MethodPost, srv.URL, ContentTypeJSON, r)
+	httpErr, ok := err.(*HTTPError)
+	if !ok || httpErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected a 503 HTTPError but got %v", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("expected 1 attempt but got %d", attempts)
+	}
+	if body != `{"id":"1"}` {
+		t.Fatalf("unexpected body: %s", body)
+	}
+
+	attempts = 0
+	_, err = c.stream(context.Background(), http.MethodPost, srv.URL, ContentTypeJSON, strings.NewReader(`{"id":"1"}`))
+	if err == nil {
+		t.Fatal("expected error but got none")
+	}
+	if attempts != 5 {
+		t.Fatalf("expected 5 attempts but got %d", attempts)
+	}
+}
+
+func TestRetryableContextDeadline(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	conf := &RetryableConfig{RetryWaitMin: time.Second, RetryWaitMax: time.Second, RetryMax: 4, NoLog: true}
+	c, err := NewRetryableConnection(srv.URL, "films", srv.Client(), conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = c.request(ctx, http.MethodGet, srv.URL, ContentTypeJSON, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected %v but got %v", context.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected to return near the deadline but took %s", elapsed)
+	}
+}
+
+type closeTracker struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed = true
+	return nil
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestRetryableClosesBodyOnError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var bodies []*closeTracker
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		// the context is done once the response has arrived, which makes
+		// the retry policy return the response along with the error
+		cancel()
+		body := &closeTracker{Reader: strings.NewReader(`{}`)}
+		bodies = append(bodies, body)
+		return &http.Response{StatusCode: http.StatusOK, Body: body, Request: r}, nil
+	})}
+
+	conf := &RetryableConfig{RetryWaitMin: time.Millisecond, RetryWaitMax: time.Millisecond, RetryMax: 1, NoLog: true}
+	c, err := NewRetryableConnection("http://localhost:8983", "films", client, conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.request(ctx, http.MethodGet, "http://localhost:8983/solr/films/select", ContentTypeJSON, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected %v but got %v", context.Canceled, err)
+	}
+	if len(bodies) != 1 || !bodies[0].closed {
+		t.Fatal("expected the body of the response to be closed")
+	}
+}
+
+func TestOnRequest(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	srv.Handle("/solr/films/select", http.StatusNotFound, "not found")
+
+	conn, err := NewConnection(srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = conn.request(context.Background(), http.MethodGet, srv.URL+"/solr/films/select", ContentTypeJSON, nil)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	var infos []RequestInfo
+	conn.OnRequest = func(info RequestInfo) {
+		infos = append(infos, info)
+	}
+	url := srv.URL + "/solr/films/select"
+	_, err = conn.request(context.Background(), http.MethodGet, url, ContentTypeJSON, nil)
+	if len(infos) != 1 {
+		t.Fatalf("expected the hook to be called once but got %d", len(infos))
+	}
+	info := infos[0]
+	if info.Method != http.MethodGet || info.URL != url || info.StatusCode != http.StatusNotFound || info.Err != err {
+		t.Fatalf("unexpected request info: %+v", info)
+	}
+	if info.Duration <= 0 {
+		t.Fatalf("expected a positive duration but got %s", info.Duration)
+	}
+}
+
+func TestOnRequestAdminAPIs(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	var infos []RequestInfo
+	hook := func(info RequestInfo) {
+		infos = append(infos, info)
+	}
+
+	ca, err := NewCoreAdmin(ctx, srv.URL, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca.SetOnRequest(hook)
+	_, err = ca.Status(ctx, "films", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	m, err := NewManagedAPI(ctx, srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.SetOnRequest(hook)
+	_, err = m.RetrieveResource(ctx, "/managed")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sa, err := NewSchemaAPI(ctx, srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sa.SetOnRequest(hook)
+	_, err = sa.RetrieveSchema(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(infos) != 3 {
+		t.Fatalf("expected the hook to be called 3 times but got %d", len(infos))
+	}
+	paths := []string{"/solr/admin/cores", "/solr/films/schema/managed", "/solr/films/schema"}
+	for i, info := range infos {
+		if !strings.HasPrefix(info.URL, srv.URL+paths[i]) || info.StatusCode != http.StatusOK {
+			t.Fatalf("unexpected request info: %+v", info)
+		}
+	}
+}
@@ -5,8 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -35,6 +39,9 @@ const (
 	CoreAdminOptionRanges            = "ranges"
 	CoreAdminOptionSplitKey          = "split.key"
 	CoreAdminOptionRequestID         = "requestid"
+	CoreAdminOptionLocation          = "location"
+	CoreAdminOptionNumberToKeep      = "numberToKeep"
+	CoreAdminOptionPropertyPrefix    = "property."
 	CoreAdminActionStatus            = "STATUS"
 	CoreAdminActionCreate            = "CREATE"
 	CoreAdminActionReload            = "RELOAD"
@@ -45,16 +52,23 @@ const (
 	CoreAdminActionSplit             = "SPLIT"
 	CoreAdminActionRequestStatus     = "REQUESTSTATUS"
 	CoreAdminActionRecover           = "REQUESTRECOVERY"
+	CoreAdminActionBackup            = "BACKUPCORE"
+	CoreAdminActionRestore           = "RESTORECORE"
 )
 
 // Errors that can be returned
 var (
 	ErrMoreParamsPath  = errors.New("only one of path, targetCore may be defined")
 	ErrMoreParamsRange = errors.New("only one of range, split.key may be defined")
+	ErrCoreNotFound    = errors.New("core not found")
+	ErrBackupName      = errors.New("the name of the backup is required")
+	ErrRequestNotFound = errors.New("async request not found")
 )
 
 // CoreCreateOpts are the optional properties that can
-// be provided when creating a new core.
+// be provided when creating a new core. Properties are
+// user-defined core properties, that can be referenced
+// in solrconfig.xml as ${key}.
 type CoreCreateOpts struct {
 	InstanceDir string
 	Config      string
@@ -64,6 +78,7 @@ type CoreCreateOpts struct {
 	Collection  string
 	Shard       string
 	AsyncID     string
+	Properties  map[string]string
 }
 
 // CoreUnloadOpts are the optional properties that can
@@ -95,6 +110,51 @@ type CoreMergeOpts struct {
 	AsyncID  string
 }
 
+// CoreBackupOpts are the properties that can be provided when backing
+// up a core. Name is required, while Location is the directory the
+// backup is stored in (the default location of the repository
+// is used if empty). NumberToKeep is the number of backups to keep
+// in the location, with the oldest ones being removed (all of them
+// are kept if zero).
+type CoreBackupOpts struct {
+	Name         string
+	Location     string
+	NumberToKeep int
+	AsyncID      string
+}
+
+// CoreRestoreOpts are the properties that can be provided when restoring
+// a core from a backup. Name is required, while Location is the
+// directory the backup is stored in.
+type CoreRestoreOpts struct {
+	Name     string
+	Location string
+	AsyncID  string
+}
+
+// AsyncStatus is the status of an asynchronous CoreAdmin API call, as
+// returned by `RequestStatus`.
+type AsyncStatus string
+
+// The available statuses of an asynchronous CoreAdmin API call
+const (
+	AsyncStatusRunning   AsyncStatus = "running"
+	AsyncStatusCompleted AsyncStatus = "completed"
+	AsyncStatusFailed    AsyncStatus = "failed"
+	AsyncStatusNotFound  AsyncStatus = "notfound"
+)
+
+// AsyncRequestError is returned when an asynchronous CoreAdmin API call
+// has failed, containing the message reported by solr.
+type AsyncRequestError struct {
+	ID      string
+	Message string
+}
+
+func (e *AsyncRequestError) Error() string {
+	return fmt.Sprintf("async request %s failed: %s", e.ID, e.Message)
+}
+
 // CoreAdminResponse represents the response from the solr core admin API. It usually
 // contains Header information, the response data or an error in case of erroneous
 // response. Also it can contain the core's or a request's status, failures
@@ -106,10 +166,51 @@ type CoreAdminResponse struct {
 	Status       map[string]*CoreStatusResponse `json:"status"`
 	ReqStatus    string                         `json:"STATUS"`
 	Response     interface{}                    `json:"response"`
-	InitFailures interface{}                    `json:"initFailures"`
+	InitFailures map[string]string              `json:"initFailures"`
 	Core         string                         `json:"core"`
 }
 
+// CoreInitError is returned when solr reports that the requested core
+// failed to initialize, e.g. due to an invalid configuration.
+type CoreInitError struct {
+	Core    string
+	Message string
+}
+
+func (e *CoreInitError) Error() string {
+	return fmt.Sprintf("core %s failed to initialize: %s", e.Core, e.Message)
+}
+
+// AsyncStatus returns the status of the asynchronous call contained
+// in the response of a `RequestStatus` call.
+func (r *CoreAdminResponse) AsyncStatus() AsyncStatus {
+	return AsyncStatus(strings.ToLower(r.ReqStatus))
+}
+
+// AsyncMessage returns the message that accompanies the status of the
+// asynchronous call in the response of a `RequestStatus` call, e.g.
+// the reason of the failure.
+func (r *CoreAdminResponse) AsyncMessage() string {
+	switch msg := r.Response.(type) {
+	case string:
+		return msg
+	case nil:
+		return ""
+	default:
+		b, _ := json.Marshal(msg)
+		return string(b)
+	}
+}
+
+// initFailure returns the initialization failure of the given core, if any.
+func (r *CoreAdminResponse) initFailure(core string) error {
+	msg, ok := r.InitFailures[core]
+	if !ok {
+		return nil
+	}
+	return &CoreInitError{Core: core, Message: msg}
+}
+
 // CoreStatusResponse contains information about a core and its status.
 type CoreStatusResponse struct {
 	Name        string        `json:"name"`
@@ -122,6 +223,37 @@ type CoreStatusResponse struct {
 	Index       *IndexData    `json:"index"`
 }
 
+// UnmarshalJSON implements the unmarshaler interface. Solr returns the uptime
+// in milliseconds, while the start time is returned either as a date
+// string or as milliseconds since the epoch.
+func (s *CoreStatusResponse) UnmarshalJSON(b []byte) error {
+	type coreStatus CoreStatusResponse
+	temp := struct {
+		*coreStatus
+		StartTime interface{} `json:"startTime"`
+		Uptime    *float64    `json:"uptime"`
+	}{coreStatus: (*coreStatus)(s)}
+	err := json.Unmarshal(b, &temp)
+	if err != nil {
+		return err
+	}
+
+	if temp.Uptime != nil {
+		s.Uptime = time.Duration(*temp.Uptime) * time.Millisecond
+	}
+
+	switch start := temp.StartTime.(type) {
+	case string:
+		s.StartTime, err = time.Parse(time.RFC3339, start)
+		if err != nil {
+			return err
+		}
+	case float64:
+		s.StartTime = time.Unix(0, int64(start)*int64(time.Millisecond)).UTC()
+	}
+	return nil
+}
+
 // IndexData contains information about a core's index.
 type IndexData struct {
 	NumDocs                 int64     `json:"numDocs"`
@@ -147,10 +279,14 @@ type UserData struct {
 	CommitTimeMSec       string `json:"commitTimeMSec"`
 }
 
-// CoreAdmin contains a connectin to solr.
+// CoreAdmin contains a connectin to solr. When UsePost is set, the actions that
+// may contain a large number of parameters (CREATE, UNLOAD, MERGEINDEXES
+// & SPLIT) send them form-encoded in the body of a POST request instead
+// of the query string, in order to not exceed the URL length limits.
 type CoreAdmin struct {
-	conn *Connection
-	Path string
+	conn    *Connection
+	Path    string
+	UsePost bool
 }
 
 // NewCoreAdmin returns a new core admin, creating a connection to solr using the provided
@@ -181,27 +317,54 @@ func (a *CoreAdmin) SetBasicAuth(username, password string) {
 	a.conn.Password = password
 }
 
+// SetOnRequest sets a hook that is called after each request with information
+// about it, e.g. in order to record metrics or tracing spans (check
+// `Connection.OnRequest`).
+func (a *CoreAdmin) SetOnRequest(hook func(info RequestInfo)) {
+	a.conn.OnRequest = hook
+}
+
 func (a *CoreAdmin) request(ctx context.Context, method, url string) (*CoreAdminResponse, error) {
-	req, err := http.NewRequest(method, url, nil)
+	return a.requestWithBody(ctx, method, url, ContentTypeJSON, nil)
+}
+
+// write sends the params of the write-style actions, using a POST request if UsePost is set.
+func (a *CoreAdmin) write(ctx context.Context, params url.Values) (*CoreAdminResponse, error) {
+	if !a.UsePost {
+		return a.request(ctx, http.MethodGet, a.Path+params.Encode())
+	}
+	url := strings.TrimSuffix(a.Path, "?")
+	return a.requestWithBody(ctx, http.MethodPost, url, ContentTypeForm, strings.NewReader(params.Encode()))
+}
+
+func (a *CoreAdmin) requestWithBody(ctx context.Context, method, url, contentType string, body io.Reader) (*CoreAdminResponse, error) {
+	req, err := http.NewRequest(method, url, body)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Content-Type", contentType)
 
-	if a.conn.Username != "" && a.conn.Password != "" {
-		req.SetBasicAuth(a.conn.Username, a.conn.Password)
+	if username, password := credentials(ctx, a.conn.Username, a.conn.Password); username != "" && password != "" {
+		req.SetBasicAuth(username, password)
 	}
 
+	start := time.Now()
 	res, err := a.conn.httpClient.Do(req.WithContext(ctx))
 	if err != nil {
+		notifyRequest(a.conn.OnRequest, method, url, start, 0, err)
 		return nil, err
 	}
-
-	var r CoreAdminResponse
 	defer res.Body.Close()
 
-	err = json.NewDecoder(res.Body).Decode(&r)
+	r, err := decodeCoreAdminResponse(res.Body)
+	notifyRequest(a.conn.OnRequest, method, url, start, res.StatusCode, err)
+	return r, err
+}
+
+func decodeCoreAdminResponse(body io.Reader) (*CoreAdminResponse, error) {
+	var r CoreAdminResponse
+	err := json.NewDecoder(body).Decode(&r)
 	if err != nil {
 		return nil, err
 	}
@@ -215,7 +378,9 @@ func (a *CoreAdmin) request(ctx context.Context, method, url string) (*CoreAdmin
 
 // Status returns the status of all running Solr cores, or status for only the named core. If the
 // noIndexInfo option is true information about the index will not be returned with a core.
-// For more info:
+// Computing the index information is expensive, therefore setting noIndexInfo speeds up
+// considerably the status of multiple cores (e.g. when monitoring). If the named core failed
+// to initialize a CoreInitError is returned alongside the response. For more info:
 // https://lucene.apache.org/solr/guide/8_5/coreadmin-api.html#coreadmin-status
 func (a *CoreAdmin) Status(ctx context.Context, core string, noIndexInfo bool) (*CoreAdminResponse, error) {
 	params := url.Values{}
@@ -227,10 +392,60 @@ func (a *CoreAdmin) Status(ctx context.Context, core string, noIndexInfo bool) (
 		params.Set(CoreAdminOptionIndexInfo, "false")
 	}
 	url := a.Path + params.Encode()
-	return a.request(ctx, http.MethodGet, url)
+	res, err := a.request(ctx, http.MethodGet, url)
+	if err != nil {
+		return res, err
+	}
+	return res, res.initFailure(core)
+}
+
+// StatusWithIndexInfo returns the status of all running Solr cores, or status for only the
+// named core, including information about their index only when indexInfo is true.
+func (a *CoreAdmin) StatusWithIndexInfo(ctx context.Context, core string, indexInfo bool) (*CoreAdminResponse, error) {
+	return a.Status(ctx, core, !indexInfo)
+}
+
+// StatusOne returns the status of the named core, including information about its index.
+// If the core does not exist ErrCoreNotFound is returned.
+func (a *CoreAdmin) StatusOne(ctx context.Context, core string) (*CoreStatusResponse, error) {
+	res, err := a.Status(ctx, core, false)
+	if err != nil {
+		return nil, err
+	}
+	status, ok := res.Status[core]
+	if !ok || status == nil || status.Name == "" {
+		return nil, ErrCoreNotFound
+	}
+	return status, nil
+}
+
+// ListCores returns the names of all the running Solr cores, sorted alphabetically.
+func (a *CoreAdmin) ListCores(ctx context.Context) ([]string, error) {
+	res, err := a.Status(ctx, "", true)
+	if err != nil {
+		return nil, err
+	}
+	cores := make([]string, 0, len(res.Status))
+	for core := range res.Status {
+		cores = append(cores, core)
+	}
+	sort.Strings(cores)
+	return cores, nil
 }
 
-// Create creates a new core and registers it. For more info:
+// CoreExists reports whether the named core is running. If the core failed
+// to initialize a CoreInitError is returned.
+func (a *CoreAdmin) CoreExists(ctx context.Context, core string) (bool, error) {
+	res, err := a.Status(ctx, core, true)
+	if err != nil {
+		return false, err
+	}
+	status, ok := res.Status[core]
+	return ok && status != nil && status.Name != "", nil
+}
+
+// Create creates a new core and registers it. If the core failed to initialize a
+// CoreInitError is returned alongside the response. For more info:
 // https://lucene.apache.org/solr/guide/8_5/coreadmin-api.html#coreadmin-create
 func (a *CoreAdmin) Create(ctx context.Context, name string, opts *CoreCreateOpts) (*CoreAdminResponse, error) {
 	params := url.Values{}
@@ -261,9 +476,15 @@ func (a *CoreAdmin) Create(ctx context.Context, name string, opts *CoreCreateOpt
 		if opts.Shard != "" {
 			params.Set(CoreAdminOptionShard, opts.Shard)
 		}
+		for key, value := range opts.Properties {
+			params.Set(CoreAdminOptionPropertyPrefix+key, value)
+		}
 	}
-	url := a.Path + params.Encode()
-	return a.request(ctx, http.MethodGet, url)
+	res, err := a.write(ctx, params)
+	if err != nil {
+		return res, err
+	}
+	return res, res.initFailure(name)
 }
 
 // Reload loads a new core from the configuration of an existing, registered Solr core. While the
@@ -329,8 +550,7 @@ func (a *CoreAdmin) Unload(ctx context.Context, core string, opts *CoreUnloadOpt
 			params.Set(CoreAdminOptionDeleteInstanceDir, "true")
 		}
 	}
-	url := a.Path + params.Encode()
-	return a.request(ctx, http.MethodGet, url)
+	return a.write(ctx, params)
 }
 
 // Merge merges one or more indexes to another index. The target core index must already exist
@@ -357,8 +577,7 @@ func (a *CoreAdmin) Merge(ctx context.Context, core string, opts *CoreMergeOpts)
 			}
 		}
 	}
-	url := a.Path + params.Encode()
-	return a.request(ctx, http.MethodGet, url)
+	return a.write(ctx, params)
 }
 
 // Split splits an index into two or more indexes. The index being split can continue to handle requests.
@@ -396,6 +615,50 @@ func (a *CoreAdmin) Split(ctx context.Context, core string, opts *CoreSplitOpts)
 			params.Set(CoreAdminOptionSplitKey, opts.SplitKey)
 		}
 	}
+	return a.write(ctx, params)
+}
+
+// Backup creates a backup of the index of a core with the provided name, e.g. for scheduled snapshots.
+// The backup is stored in the provided location, which must be accessible by the solr server.
+// Older backups in the location are only removed when `NumberToKeep` is set.
+func (a *CoreAdmin) Backup(ctx context.Context, core string, opts *CoreBackupOpts) (*CoreAdminResponse, error) {
+	if opts == nil || opts.Name == "" {
+		return nil, ErrBackupName
+	}
+	params := url.Values{}
+	params.Set(CoreAdminOptionAction, CoreAdminActionBackup)
+	params.Set(CoreAdminOptionCore, core)
+	params.Set(CoreAdminOptionName, opts.Name)
+	if opts.Location != "" {
+		params.Set(CoreAdminOptionLocation, opts.Location)
+	}
+	if opts.NumberToKeep > 0 {
+		params.Set(CoreAdminOptionNumberToKeep, strconv.Itoa(opts.NumberToKeep))
+	}
+	if opts.AsyncID != "" {
+		params.Set(CoreAdminOptionAsync, opts.AsyncID)
+	}
+	url := a.Path + params.Encode()
+	return a.request(ctx, http.MethodGet, url)
+}
+
+// Restore replaces the index of a core with the one stored in the backup with the provided
+// name, previously created using `Backup`. The core keeps serving requests from the old
+// index until the restore is completed.
+func (a *CoreAdmin) Restore(ctx context.Context, core string, opts *CoreRestoreOpts) (*CoreAdminResponse, error) {
+	if opts == nil || opts.Name == "" {
+		return nil, ErrBackupName
+	}
+	params := url.Values{}
+	params.Set(CoreAdminOptionAction, CoreAdminActionRestore)
+	params.Set(CoreAdminOptionCore, core)
+	params.Set(CoreAdminOptionName, opts.Name)
+	if opts.Location != "" {
+		params.Set(CoreAdminOptionLocation, opts.Location)
+	}
+	if opts.AsyncID != "" {
+		params.Set(CoreAdminOptionAsync, opts.AsyncID)
+	}
 	url := a.Path + params.Encode()
 	return a.request(ctx, http.MethodGet, url)
 }
@@ -411,6 +674,39 @@ func (a *CoreAdmin) RequestStatus(ctx context.Context, id string) (*CoreAdminRes
 	return a.request(ctx, http.MethodGet, url)
 }
 
+// WaitForRequest polls the status of an already submitted asynchronous CoreAdmin API call every
+// pollInterval, until it is either completed or failed. In the latter case an AsyncRequestError
+// is returned alongside the response, while ErrRequestNotFound is returned when solr does
+// not know about the call. It stops polling when the context is done. A non-positive
+// pollInterval defaults to one second.
+func (a *CoreAdmin) WaitForRequest(ctx context.Context, id string, pollInterval time.Duration) (*CoreAdminResponse, error) {
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		res, err := a.RequestStatus(ctx, id)
+		if err != nil {
+			return res, err
+		}
+		switch res.AsyncStatus() {
+		case AsyncStatusCompleted:
+			return res, nil
+		case AsyncStatusFailed:
+			return res, &AsyncRequestError{ID: id, Message: res.AsyncMessage()}
+		case AsyncStatusNotFound:
+			return res, ErrRequestNotFound
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
 // Recover manually asks a core to recover by synching with the leader. This should be considered
 // an "expert" level command and should be used in situations where the node (SorlCloud replica)
 // is unable to become active automatically. For more info
@@ -2,8 +2,15 @@ package solr
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/mecenat/solr/solrtest"
 )
 
 func TestNewCoreAdminInvalidUrl(t *testing.T) {
@@ -56,3 +63,349 @@ func TestSplitBothRangesAndKey(t *testing.T) {
 		t.Fatal("shouldn't be possible to run with both ranges & splitKey")
 	}
 }
+
+func TestCoreInitFailures(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	srv.Handle("/solr/admin/cores", http.StatusOK, `{"responseHeader":{"status":0,"QTime":1},
+		"initFailures":{"films":"org.apache.solr.common.SolrException: Could not load conf"},
+		"status":{"films":{}}}`)
+
+	ca, err := NewCoreAdmin(context.Background(), srv.URL, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := ca.Create(context.Background(), "films", nil)
+	initErr, ok := err.(*CoreInitError)
+	if !ok {
+		t.Fatalf("expected a CoreInitError but got %v", err)
+	}
+	if initErr.Core != "films" || initErr.Message != "org.apache.solr.common.SolrException: Could not load conf" {
+		t.Fatalf("unexpected error: %v", initErr)
+	}
+	if res == nil {
+		t.Fatal("expected the response to be returned alongside the error")
+	}
+
+	_, err = ca.Status(context.Background(), "films", true)
+	if _, ok := err.(*CoreInitError); !ok {
+		t.Fatalf("expected a CoreInitError but got %v", err)
+	}
+
+	_, err = ca.Status(context.Background(), "other", true)
+	if err != nil {
+		t.Fatalf("expected no error for a healthy core but got %v", err)
+	}
+}
+
+func TestBackupRestoreParams(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+
+	ca, err := NewCoreAdmin(context.Background(), srv.URL, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ca.Backup(context.Background(), "films", nil)
+	if err != ErrBackupName {
+		t.Fatalf("expected %v but got %v", ErrBackupName, err)
+	}
+	_, err = ca.Restore(context.Background(), "films", &CoreRestoreOpts{Location: "/backups"})
+	if err != ErrBackupName {
+		t.Fatalf("expected %v but got %v", ErrBackupName, err)
+	}
+
+	_, err = ca.Backup(context.Background(), "films", &CoreBackupOpts{Name: "daily", Location: "/backups", NumberToKeep: 7, AsyncID: "b1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req := srv.LastRequest()
+	solrtest.AssertParam(t, req, "action", "BACKUPCORE")
+	solrtest.AssertParam(t, req, "core", "films")
+	solrtest.AssertParam(t, req, "name", "daily")
+	solrtest.AssertParam(t, req, "location", "/backups")
+	solrtest.AssertParam(t, req, "numberToKeep", "7")
+	solrtest.AssertParam(t, req, "async", "b1")
+
+	_, err = ca.Restore(context.Background(), "films", &CoreRestoreOpts{Name: "daily", Location: "/backups"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req = srv.LastRequest()
+	solrtest.AssertParam(t, req, "action", "RESTORECORE")
+	solrtest.AssertParam(t, req, "name", "daily")
+	solrtest.AssertParam(t, req, "location", "/backups")
+	if req.Query.Has("async") {
+		t.Fatal("async should not be set without an id")
+	}
+}
+
+func TestWaitForRequest(t *testing.T) {
+	statuses := []string{"running", "running", "completed"}
+	polls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("requestid") == "failing" {
+			w.Write([]byte(`{"responseHeader":{"status":0},"STATUS":"failed","Response":"Error CREATEing SolrCore"}`))
+			return
+		}
+		status := statuses[polls]
+		polls++
+		w.Write([]byte(`{"responseHeader":{"status":0},"STATUS":"` + status + `","Response":"TaskId: req1"}`))
+	}))
+	defer srv.Close()
+
+	ca, err := NewCoreAdmin(context.Background(), srv.URL, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := ca.WaitForRequest(context.Background(), "req1", time.Millisecond)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if polls != 3 || res.AsyncStatus() != AsyncStatusCompleted || res.AsyncMessage() != "TaskId: req1" {
+		t.Fatalf("unexpected result after %d polls: %s %s", polls, res.AsyncStatus(), res.AsyncMessage())
+	}
+
+	_, err = ca.WaitForRequest(context.Background(), "failing", time.Millisecond)
+	asyncErr, ok := err.(*AsyncRequestError)
+	if !ok || asyncErr.ID != "failing" || asyncErr.Message != "Error CREATEing SolrCore" {
+		t.Fatalf("expected an AsyncRequestError but got %v", err)
+	}
+
+	polls = 0
+	statuses = []string{"running", "running", "running"}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = ca.WaitForRequest(ctx, "req1", time.Second)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected %v but got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestCoreAdminUsePost(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+
+	ca, err := NewCoreAdmin(context.Background(), srv.URL, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca.UsePost = true
+
+	_, err = ca.Split(context.Background(), "films", &CoreSplitOpts{TargetCore: []string{"films1", "films2"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req := srv.LastRequest()
+	if req.Method != http.MethodPost || req.Path != "/solr/admin/cores" {
+		t.Fatalf("unexpected request: %s %s", req.Method, req.Path)
+	}
+	if req.ContentType != ContentTypeForm {
+		t.Fatalf("unexpected content type: %s", req.ContentType)
+	}
+	if len(req.Query) != 0 {
+		t.Fatalf("expected no query params but got %v", req.Query)
+	}
+	body, err := url.ParseQuery(string(req.Body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body.Get("action") != "SPLIT" || body.Get("core") != "films" || len(body["targetCore"]) != 2 {
+		t.Fatalf("unexpected body: %s", req.Body)
+	}
+
+	_, err = ca.Status(context.Background(), "films", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req = srv.LastRequest()
+	if req.Method != http.MethodGet {
+		t.Fatalf("expected STATUS to use GET but got %s", req.Method)
+	}
+	solrtest.AssertParam(t, req, "action", "STATUS")
+}
+
+func TestCreateProperties(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+
+	ca, err := NewCoreAdmin(context.Background(), srv.URL, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := &CoreCreateOpts{
+		ConfigSet:  "_default",
+		Properties: map[string]string{"foo": "bar", "solr.autoCommit.maxTime": "15000"},
+	}
+	_, err = ca.Create(context.Background(), "films", opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req := srv.LastRequest()
+	solrtest.AssertParam(t, req, "action", "CREATE")
+	solrtest.AssertParam(t, req, "property.foo", "bar")
+	solrtest.AssertParam(t, req, "property.solr.autoCommit.maxTime", "15000")
+}
+
+func TestCoreStatusUptime(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	srv.Handle("/solr/admin/cores", http.StatusOK, `{"responseHeader":{"status":0,"QTime":2},
+		"initFailures":{},
+		"status":{"films":{"name":"films","instanceDir":"/var/solr/data/films",
+			"dataDir":"/var/solr/data/films/data/","config":"solrconfig.xml","schema":"managed-schema",
+			"startTime":"2020-05-11T09:34:18.345Z","uptime":90061,
+			"index":{"numDocs":1100,"maxDoc":1100,"deletedDocs":0,"version":14,"segmentCount":1,
+				"current":true,"hasDeletions":false,"lastModified":"2020-05-11T09:35:02.101Z",
+				"sizeInBytes":503245,"size":"491.45 KB"}}}}`)
+
+	ca, err := NewCoreAdmin(context.Background(), srv.URL, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err := ca.StatusOne(context.Background(), "films")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.Uptime != 90061*time.Millisecond {
+		t.Fatalf("expected an uptime of 1m30.061s but got %s", status.Uptime)
+	}
+	expected := time.Date(2020, 5, 11, 9, 34, 18, 345000000, time.UTC)
+	if !status.StartTime.Equal(expected) {
+		t.Fatalf("expected a start time of %s but got %s", expected, status.StartTime)
+	}
+	if status.Index == nil || status.Index.NumDocs != 1100 || status.Name != "films" {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+
+	var s CoreStatusResponse
+	err = json.Unmarshal([]byte(`{"startTime":1589189658345}`), &s)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !s.StartTime.Equal(expected) {
+		t.Fatalf("expected a start time of %s but got %s", expected, s.StartTime)
+	}
+}
+
+func TestListCores(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	srv.Handle("/solr/admin/cores", http.StatusOK, `{"responseHeader":{"status":0,"QTime":1},
+		"initFailures":{},
+		"status":{"films":{"name":"films"},"actors":{"name":"actors"},"directors":{"name":"directors"}}}`)
+
+	ca, err := NewCoreAdmin(context.Background(), srv.URL, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cores, err := ca.ListCores(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(cores, ",") != "actors,directors,films" {
+		t.Fatalf("unexpected cores: %v", cores)
+	}
+	req := srv.LastRequest()
+	solrtest.AssertParam(t, req, "action", "STATUS")
+	solrtest.AssertParam(t, req, "indexInfo", "false")
+	if req.Query.Has("core") {
+		t.Fatal("core should not be set when listing the cores")
+	}
+}
+
+func TestCoreExists(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	srv.Handle("/solr/admin/cores", http.StatusOK, `{"responseHeader":{"status":0,"QTime":1},
+		"initFailures":{},"status":{"films":{"name":"films"},"other":{}}}`)
+
+	ca, err := NewCoreAdmin(context.Background(), srv.URL, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	exists, err := ca.CoreExists(context.Background(), "films")
+	if err != nil || !exists {
+		t.Fatalf("expected films to exist but got %v, %v", exists, err)
+	}
+	solrtest.AssertParam(t, srv.LastRequest(), "core", "films")
+
+	exists, err = ca.CoreExists(context.Background(), "other")
+	if err != nil || exists {
+		t.Fatalf("expected other to not exist but got %v, %v", exists, err)
+	}
+}
+
+func TestStatusWithIndexInfo(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	srv.Handle("/solr/admin/cores", http.StatusOK, `{"responseHeader":{"status":0,"QTime":1},"initFailures":{},
+		"status":{"films":{"name":"films","instanceDir":"/var/solr/data/films","uptime":5000,
+			"index":{"numDocs":42,"maxDoc":43,"deletedDocs":1,"segmentCount":2,"current":true}}}}`)
+
+	ca, err := NewCoreAdmin(context.Background(), srv.URL, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ca.StatusWithIndexInfo(context.Background(), "films", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req := srv.LastRequest()
+	solrtest.AssertParam(t, req, "action", "STATUS")
+	solrtest.AssertParam(t, req, "core", "films")
+	solrtest.AssertParam(t, req, "indexInfo", "false")
+
+	res, err := ca.StatusWithIndexInfo(context.Background(), "films", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if srv.LastRequest().Query.Has("indexInfo") {
+		t.Fatal("indexInfo should be left to its default when requested")
+	}
+	status := res.Status["films"]
+	if status == nil || status.Index == nil || status.Index.NumDocs != 42 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+}
+
+func TestStatusOne(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	srv.Handle("/solr/admin/cores", http.StatusOK, `{"responseHeader":{"status":0,"QTime":1},"initFailures":{},
+		"status":{"films":{"name":"films","instanceDir":"/var/solr/data/films","uptime":5000,
+			"index":{"numDocs":42,"maxDoc":43,"deletedDocs":1,"segmentCount":2,"current":true}}}}`)
+
+	ca, err := NewCoreAdmin(context.Background(), srv.URL, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := ca.StatusOne(context.Background(), "films")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.Name != "films" || status.InstanceDir != "/var/solr/data/films" || status.Uptime != 5*time.Second {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	if status.Index == nil || status.Index.NumDocs != 42 || status.Index.DeletedDocs != 1 || !status.Index.Current {
+		t.Fatalf("unexpected index info: %+v", status.Index)
+	}
+	req := srv.LastRequest()
+	solrtest.AssertParam(t, req, "core", "films")
+	if req.Query.Has("indexInfo") {
+		t.Fatal("indexInfo should be left to its default")
+	}
+
+	srv.Handle("/solr/admin/cores", http.StatusOK, `{"responseHeader":{"status":0,"QTime":1},"initFailures":{},
+		"status":{"missing":{}}}`)
+	_, err = ca.StatusOne(context.Background(), "missing")
+	if err != ErrCoreNotFound {
+		t.Fatalf("expected %v but got %v", ErrCoreNotFound, err)
+	}
+}
@@ -0,0 +1,80 @@
+package solr
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+// Cursor Options
+const (
+	OptionCursorMark  = "cursorMark"
+	CursorMarkInitial = "*"
+)
+
+// Possible errors returned from improper use of a cursor
+var (
+	ErrCursorSortRequired = errors.New("a sort including the uniqueKey field is required when using a cursor")
+	ErrCursorInvalidRows  = errors.New("the rows parameter must be greater than zero when using a cursor")
+	ErrCursorNotSupported = errors.New("no nextCursorMark returned, the request handler does not support cursors")
+)
+
+// SetCursorMark sets the cursor mark of the query, used to fetch the next page of the
+// results (check `Response.NextCursorMark`). An empty mark sets the initial mark
+// `*`. Solr requires a deterministic sort that includes the uniqueKey field,
+// therefore the sort must be set before calling this method.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/pagination-of-results.html#fetching-a-large-number-of-sorted-results-cursors
+func (q *Query) SetCursorMark(mark string) error {
+	if q.params.Get(OptionSort) == "" {
+		return ErrCursorSortRequired
+	}
+	if mark == "" {
+		mark = CursorMarkInitial
+	}
+	q.params.Set(OptionCursorMark, mark)
+	q.params.Del(OptionStart)
+	return nil
+}
+
+// IterateAll fetches all the results of the given query page by page using a cursor,
+// calling fn with the response of each page until all pages have been fetched or
+// fn returns an error. The query must have a sort including the uniqueKey field,
+// and the page size can be set using the rows parameter, which must be greater
+// than zero. The given query is not modified, so it can be reused afterwards.
+// ErrCursorNotSupported is returned if a response contains no nextCursorMark,
+// e.g. when the request handler ignores the cursor.
+func IterateAll(ctx context.Context, c Client, q *Query, fn func(*Response) error) error {
+	if rows := q.params.Get(OptionRows); rows != "" {
+		n, err := strconv.Atoi(rows)
+		if err != nil || n <= 0 {
+			return ErrCursorInvalidRows
+		}
+	}
+
+	q = q.clone()
+	mark := CursorMarkInitial
+	for {
+		err := q.SetCursorMark(mark)
+		if err != nil {
+			return err
+		}
+
+		res, err := c.Search(ctx, q)
+		if err != nil {
+			return err
+		}
+		if res.NextCursorMark == "" {
+			return ErrCursorNotSupported
+		}
+		if res.IsLastPage(mark) {
+			return nil
+		}
+
+		err = fn(res)
+		if err != nil {
+			return err
+		}
+		mark = res.NextCursorMark
+	}
+}
@@ -0,0 +1,99 @@
+package solr
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/mecenat/solr/solrtest"
+)
+
+func TestSetCursorMarkNoSort(t *testing.T) {
+	q := NewQuery(nil)
+	err := q.SetCursorMark("")
+	if err != ErrCursorSortRequired {
+		t.Fatalf("expected %v but got %v", ErrCursorSortRequired, err)
+	}
+}
+
+func TestSetCursorMark(t *testing.T) {
+	q := NewQuery(nil)
+	q.SetSort("id asc")
+	q.SetStart(10)
+	err := q.SetCursorMark("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q.params.Get("cursorMark") != CursorMarkInitial {
+		t.Fatal("initial cursor mark not registered")
+	}
+	if q.params.Get("start") != "" {
+		t.Fatal("start param should be removed when using a cursor")
+	}
+}
+
+func TestIsLastPage(t *testing.T) {
+	res := &Response{NextCursorMark: "AoE"}
+	if res.IsLastPage(CursorMarkInitial) {
+		t.Fatal("shouldn't be the last page when the mark changed")
+	}
+	if !res.IsLastPage("AoE") {
+		t.Fatal("should be the last page when the mark is unchanged")
+	}
+
+	res = &Response{}
+	if !res.IsLastPage(CursorMarkInitial) {
+		t.Fatal("should be the last page when no next mark was returned")
+	}
+}
+
+func TestIterateAll(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	srv.Handle("/solr/films/select", http.StatusOK, `{"responseHeader":{"status":0},"response":{"numFound":1,"start":0,"docs":[{"id":"1"}]},"nextCursorMark":"AoE"}`)
+	c := newTestClient(t, srv)
+
+	q := NewQuery(nil)
+	q.SetSort("id asc")
+	q.SetRows(1)
+	var pages int
+	err := IterateAll(context.Background(), c, q, func(res *Response) error {
+		pages++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pages != 1 {
+		t.Fatalf("expected 1 page but got %d", pages)
+	}
+	reqs := srv.Requests()
+	if len(reqs) != 2 {
+		t.Fatalf("expected 2 requests but got %d", len(reqs))
+	}
+	solrtest.AssertParam(t, reqs[0], "cursorMark", CursorMarkInitial)
+	solrtest.AssertParam(t, reqs[1], "cursorMark", "AoE")
+	if q.params.Get("cursorMark") != "" {
+		t.Fatalf("the given query should not be modified but got cursorMark %s", q.params.Get("cursorMark"))
+	}
+
+	srv.Reset()
+	srv.Handle("/solr/films/select", http.StatusOK, `{"responseHeader":{"status":0},"response":{"numFound":1,"start":0,"docs":[{"id":"1"}]}}`)
+	pages = 0
+	err = IterateAll(context.Background(), c, q, func(res *Response) error {
+		pages++
+		return nil
+	})
+	if err != ErrCursorNotSupported {
+		t.Fatalf("expected %v but got %v", ErrCursorNotSupported, err)
+	}
+	if pages != 0 {
+		t.Fatalf("expected no pages but got %d", pages)
+	}
+
+	q.SetRows(0)
+	err = IterateAll(context.Background(), c, q, func(res *Response) error { return nil })
+	if err != ErrCursorInvalidRows {
+		t.Fatalf("expected %v but got %v", ErrCursorInvalidRows, err)
+	}
+}
@@ -0,0 +1,44 @@
+package solr
+
+import (
+	"errors"
+	"net/url"
+)
+
+// Commands available to the DataImportHandler
+const (
+	DataImportCommandFullImport  DataImportCommand = "full-import"
+	DataImportCommandDeltaImport DataImportCommand = "delta-import"
+	DataImportCommandStatus      DataImportCommand = "status"
+	DataImportCommandReloadConf  DataImportCommand = "reload-config"
+	DataImportCommandAbort       DataImportCommand = "abort"
+	OptionDataImportCommand                        = "command"
+)
+
+// ErrInvalidDataImportCommand is returned when an unknown DataImportHandler command is used
+var ErrInvalidDataImportCommand = errors.New("invalid data import command, please use one of the provided ones")
+
+// DataImportCommand is used to restrict the available commands that
+// can be sent to the DataImportHandler.
+type DataImportCommand string
+
+func (c DataImportCommand) String() string {
+	return string(c)
+}
+
+func (c DataImportCommand) isValid() bool {
+	return c == DataImportCommandFullImport || c == DataImportCommandDeltaImport ||
+		c == DataImportCommandStatus || c == DataImportCommandReloadConf || c == DataImportCommandAbort
+}
+
+func formatDataImportQuery(command DataImportCommand, params map[string]string) (string, error) {
+	if !command.isValid() {
+		return "", ErrInvalidDataImportCommand
+	}
+	vals := make(url.Values)
+	for k, v := range params {
+		vals.Set(k, v)
+	}
+	vals.Set(OptionDataImportCommand, command.String())
+	return vals.Encode(), nil
+}
@@ -0,0 +1,19 @@
+package solr
+
+import "testing"
+
+func TestFormatDataImportQuery(t *testing.T) {
+	_, err := formatDataImportQuery("invalid", nil)
+	if err == nil {
+		t.Fatal("shouldn't accept an invalid command")
+	}
+
+	actual, err := formatDataImportQuery(DataImportCommandFullImport, map[string]string{"clean": "false"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "clean=false&command=full-import"
+	if actual != expected {
+		t.Fatalf("expected %s but got %s", expected, actual)
+	}
+}
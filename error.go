@@ -2,10 +2,25 @@ package solr
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"regexp"
 	"strings"
 )
 
+// ErrUnknownField is matched (using `errors.Is`) by the errors returned when indexing
+// a document with a field that is not defined in the schema and does not match any
+// dynamic field. The name of the field is found with `ResponseError.UnknownField`.
+var ErrUnknownField = errors.New("unknown field")
+
+// ErrVersionConflict is matched (using `errors.Is`) by the errors returned when an
+// update is rejected due to the `_version_` of the document not matching the
+// one provided (check `UpdatedFields.SetVersion`).
+var ErrVersionConflict = errors.New("version conflict")
+
+var unknownFieldRegexp = regexp.MustCompile(`unknown field '([^']*)'`)
+
 // ErrorDetail is an interface to interpret the details of an error. Solr
 // tends to be inconsistent about the type of the detail, therefore
 // an interface is needed to cover all possible scenarios.
@@ -45,14 +60,41 @@ func (d *ErrorDetailString) Item() map[string]interface{} {
 	return map[string]interface{}{}
 }
 
+// HTTPError is returned when solr responds with an unsuccessful status code
+// without a solr error in the body, e.g. an HTML page for a 404 response
+// or a stack trace for a 500 one.
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+// maxHTTPErrorBody is the maximum length of the body included in the message
+// of an HTTPError, as the body can be a whole HTML page.
+const maxHTTPErrorBody = 512
+
+// Is reports whether the error matches the target, e.g. ErrVersionConflict.
+func (e *HTTPError) Is(target error) bool {
+	return target == ErrVersionConflict && e.StatusCode == http.StatusConflict
+}
+
+func (e *HTTPError) Error() string {
+	body := e.Body
+	if len(body) > maxHTTPErrorBody {
+		body = body[:maxHTTPErrorBody] + "..."
+	}
+	return fmt.Sprintf("unexpected response status %d: %s", e.StatusCode, body)
+}
+
 // ResponseError is populated in the event the response from the solr
 // server is erroneous. It contains the status code, a message
-// and some metadata about the error's class
+// and some metadata about the error's class. When solr includes
+// the stack trace of the error, it is contained in Trace.
 type ResponseError struct {
 	Code    float64       `json:"code"`
 	Message string        `json:"msg"`
 	Meta    []string      `json:"metadata"`
 	Details []ErrorDetail `json:"details"`
+	Trace   string        `json:"trace"`
 }
 
 func (r *ResponseError) Error() string {
@@ -66,6 +108,29 @@ func (r *ResponseError) Error() string {
 	return r.Message
 }
 
+// UnknownField returns the name of the field that caused the error, when it was
+// caused by indexing a document with a field that is unknown to the schema.
+// Otherwise an empty string is returned.
+func (r *ResponseError) UnknownField() string {
+	m := unknownFieldRegexp.FindStringSubmatch(r.Message)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// Is reports whether the error matches the target, which is used to detect
+// specific errors with `errors.Is`, e.g. ErrUnknownField & ErrVersionConflict.
+func (r *ResponseError) Is(target error) bool {
+	switch target {
+	case ErrUnknownField:
+		return r.UnknownField() != ""
+	case ErrVersionConflict:
+		return r.Code == http.StatusConflict
+	}
+	return false
+}
+
 // UnmarshalJSON implements the unmarshaler interface
 func (r *ResponseError) UnmarshalJSON(b []byte) error {
 	var temp map[string]interface{}
@@ -84,6 +149,11 @@ func (r *ResponseError) UnmarshalJSON(b []byte) error {
 		r.Message = msg
 	}
 
+	trace, ok := temp["trace"].(string)
+	if ok {
+		r.Trace = trace
+	}
+
 	metadata, ok := temp["metadata"].([]interface{})
 	if ok {
 		for _, meta := range metadata {
@@ -0,0 +1,73 @@
+package solr
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestResponseErrorUnmarshal(t *testing.T) {
+	input := `{"metadata":["error-class","org.apache.solr.common.SolrException"],"msg":"ERROR: unknown field 'foo'","trace":"org.apache.solr.common.SolrException: ERROR","code":400}`
+	var e ResponseError
+	err := json.Unmarshal([]byte(input), &e)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Code != 400 || e.Message != "ERROR: unknown field 'foo'" || len(e.Meta) != 2 {
+		t.Fatalf("unexpected error: %+v", e)
+	}
+	if e.Trace != "org.apache.solr.common.SolrException: ERROR" {
+		t.Fatalf("unexpected trace: %s", e.Trace)
+	}
+}
+
+func TestDecodeHTTPError(t *testing.T) {
+	html := "<html><body><h2>HTTP ERROR 404 Not Found</h2></body></html>"
+	_, err := decodeResponse(http.StatusNotFound, strings.NewReader(html), false, false)
+	httpErr, ok := err.(*HTTPError)
+	if !ok {
+		t.Fatalf("expected an HTTPError but got %v", err)
+	}
+	if httpErr.StatusCode != http.StatusNotFound || httpErr.Body != html {
+		t.Fatalf("unexpected error: %+v", httpErr)
+	}
+
+	body := `{"responseHeader":{"status":400,"QTime":1},"error":{"metadata":[],"msg":"undefined field foo","code":400}}`
+	res, err := decodeResponse(http.StatusBadRequest, strings.NewReader(body), false, false)
+	resErr, ok := err.(*ResponseError)
+	if !ok {
+		t.Fatalf("expected a ResponseError but got %v", err)
+	}
+	if resErr.Message != "undefined field foo" || res == nil {
+		t.Fatalf("unexpected error: %+v", resErr)
+	}
+
+	_, err = decodeResponse(http.StatusOK, strings.NewReader(html), false, false)
+	if _, ok := err.(*HTTPError); ok || err == nil {
+		t.Fatalf("expected a decoding error for a successful status but got %v", err)
+	}
+
+	long := &HTTPError{StatusCode: 500, Body: strings.Repeat("a", 1000)}
+	if len(long.Error()) > 600 {
+		t.Fatal("expected the body to be truncated in the error message")
+	}
+}
+
+func TestUnknownFieldError(t *testing.T) {
+	body := `{"responseHeader":{"status":400,"QTime":1},"error":{"metadata":["error-class","org.apache.solr.common.SolrException"],"msg":"ERROR: [doc=1] unknown field 'foo'","code":400}}`
+	_, err := decodeResponse(http.StatusBadRequest, strings.NewReader(body), false, false)
+	if !errors.Is(err, ErrUnknownField) {
+		t.Fatalf("expected %v but got %v", ErrUnknownField, err)
+	}
+	var resErr *ResponseError
+	if !errors.As(err, &resErr) || resErr.UnknownField() != "foo" {
+		t.Fatalf("expected the unknown field to be foo but got %v", err)
+	}
+
+	other := &ResponseError{Message: "undefined field bar"}
+	if errors.Is(other, ErrUnknownField) || other.UnknownField() != "" {
+		t.Fatal("expected other errors not to match ErrUnknownField")
+	}
+}
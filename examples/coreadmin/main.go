@@ -107,9 +107,9 @@ func main() {
 	}
 	fmt.Println(res.Header)
 
-	res, err = ca.RequestStatus(ctx, reqID)
+	res, err = ca.WaitForRequest(ctx, reqID, time.Second)
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Println(res.ReqStatus)
+	fmt.Println(res.AsyncStatus())
 }
@@ -48,51 +48,15 @@ func main() {
 	}
 	fmt.Println(res.Header)
 
-	name := &solr.Field{
-		Name: "name",
-		Type: "string",
-	}
-	res, err = sa.AddField(ctx, name)
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println(res.Header)
-
-	year := &solr.Field{
-		Name: "year",
-		Type: "string",
-	}
-	res, err = sa.AddField(ctx, year)
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println(res.Header)
-
-	genre := &solr.Field{
-		Name: "genre",
-		Type: "text_general",
-	}
-	res, err = sa.AddField(ctx, genre)
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println(res.Header)
-
-	directedBy := &solr.Field{
-		Name: "directed_by",
-		Type: "text_general",
-	}
-	res, err = sa.AddField(ctx, directedBy)
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println(res.Header)
-
-	seen := &solr.Field{
-		Name: "seen_counter",
-		Type: "pint",
+	// The rest of the fields can be added at once, in a single request
+	fields := []*solr.Field{
+		{Name: "name", Type: "string"},
+		{Name: "year", Type: "string"},
+		{Name: "genre", Type: "text_general"},
+		{Name: "directed_by", Type: "text_general"},
+		{Name: "seen_counter", Type: "pint"},
 	}
-	res, err = sa.AddField(ctx, seen)
+	res, err = sa.AddFields(ctx, fields)
 	if err != nil {
 		log.Fatal(err)
 	}
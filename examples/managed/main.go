@@ -100,13 +100,8 @@ func main() {
 	}
 	fmt.Println(res.Synonyms.ManagedMap)
 
-	// in order for our edits to be saved we need to reload the core, using the CoreAPI
-	ca, err := solr.NewCoreAdmin(ctx, "http://localhost:8983", http.DefaultClient)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	caRes, err := ca.Reload(ctx, "films")
+	// in order for our edits to be saved we need to reload the core
+	caRes, err := ma.ReloadCore(ctx)
 	if err != nil {
 		log.Fatal(err)
 	}
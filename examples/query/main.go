@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
@@ -88,17 +87,12 @@ func main() {
 	fmt.Println(res.Data.NumFound)
 	fmt.Println(len(res.Data.Docs))
 
-	// The Docs & Doc structs offer a helper ToBytes method
+	// The Docs & Doc structs offer a helper Unmarshal method
 	// which can easily help you unmarshal them to your
 	// structs
 	var films []*data.Film
 
-	fBytes, err := res.Data.Docs.ToBytes()
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	err = json.Unmarshal(fBytes, &films)
+	err = res.Data.Docs.Unmarshal(&films)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -153,7 +147,7 @@ func main() {
 	})
 	q5.SetQuery("*:*")
 	// Get all fields as well as score
-	q5.AddField("*,score")
+	q5.IncludeScore()
 	q5.Collapse(&solr.CollapseParams{
 		Field: "year",
 	})
@@ -188,10 +182,11 @@ func main() {
 		log.Fatal(err)
 	}
 
-	fmt.Println(res.Grouped.ByFieldOrFunc["year"].Matches)
-	fmt.Println(res.Grouped.ByFieldOrFunc["year"].NumberOfGroups)
-	i := res.Grouped.ByFieldOrFunc["year"].Groups[0].Value.(string)
-	no := res.Grouped.ByFieldOrFunc["year"].Groups[0].DocList.NumFound
+	years := res.Grouped.Field("year")
+	fmt.Println(years.Matches)
+	fmt.Println(years.NumberOfGroups)
+	i := years.Groups[0].ValueString()
+	no := years.Groups[0].DocList.NumFound
 	fmt.Println(i, no)
 
 	// -----------
@@ -239,7 +234,7 @@ func main() {
 	}
 
 	fmt.Println(res.Grouped)
-	no = res.Grouped.ByQuery["year:(!1968)"].DocList.NumFound
+	no = res.Grouped.Query("year:(!1968)").DocList.NumFound
 	fmt.Println("!year:1968", no)
 
 	// Clear the database, playtime is over
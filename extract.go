@@ -0,0 +1,43 @@
+package solr
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// ExtractOptions contains options for indexing rich documents through the
+// ExtractingRequestHandler (Solr Cell). Those include:
+// FieldMappings: Maps the fields extracted by Tika to fields of the
+// schema (fmap.<source>=<target>)
+// WriteOptions: The options of every write action
+type ExtractOptions struct {
+	FieldMappings map[string]string
+	WriteOptions
+}
+
+func (opts *ExtractOptions) writeOptions() *WriteOptions {
+	if opts == nil {
+		return nil
+	}
+	return &opts.WriteOptions
+}
+
+func formatExtractQuery(literals map[string]string, opts *ExtractOptions) url.Values {
+	q := make(url.Values)
+	if opts != nil {
+		q = opts.writeOptions().formatQueryFromOpts()
+		for source, target := range opts.FieldMappings {
+			q.Set("fmap."+source, target)
+		}
+	}
+	for field, value := range literals {
+		q.Set("literal."+field, value)
+	}
+	return q
+}
+
+func extract(ctx context.Context, conn connection, url, contentType string, file io.Reader) (*Response, error) {
+	return conn.stream(ctx, http.MethodPost, url, contentType, file)
+}
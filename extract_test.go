@@ -0,0 +1,52 @@
+package solr
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mecenat/solr/solrtest"
+)
+
+func TestFormatExtractQuery(t *testing.T) {
+	opts := &ExtractOptions{
+		FieldMappings: map[string]string{"content": "text"},
+		WriteOptions:  WriteOptions{Commit: true},
+	}
+	actual := formatExtractQuery(map[string]string{"id": "doc1"}, opts).Encode()
+	expected := "commit=true&fmap.content=text&literal.id=doc1"
+	if actual != expected {
+		t.Fatalf("expected %s but got %s", expected, actual)
+	}
+
+	actual = formatExtractQuery(nil, nil).Encode()
+	if actual != "" {
+		t.Fatalf("expected empty query but got %s", actual)
+	}
+}
+
+func TestExtractAndIndex(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	c := newTestClient(t, srv)
+
+	doc := "%PDF-1.4 fake document"
+	literals := map[string]string{"id": "doc1", "category": "manuals"}
+	_, err := c.ExtractAndIndex(context.Background(), strings.NewReader(doc), "application/pdf", literals, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req := srv.LastRequest()
+	if req.Method != http.MethodPost || req.Path != "/solr/films/update/extract" {
+		t.Fatalf("unexpected request: %s %s", req.Method, req.Path)
+	}
+	if req.ContentType != "application/pdf" {
+		t.Fatalf("unexpected content type: %s", req.ContentType)
+	}
+	solrtest.AssertParam(t, req, "literal.id", "doc1")
+	solrtest.AssertParam(t, req, "literal.category", "manuals")
+	if string(req.Body) != doc {
+		t.Fatalf("unexpected body: %s", req.Body)
+	}
+}
@@ -0,0 +1,114 @@
+package solr
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Highlighting Options and methods
+const (
+	OptionHighlight              = "hl"
+	OptionHighlightFields        = "hl.fl"
+	OptionHighlightQuery         = "hl.q"
+	OptionHighlightMethod        = "hl.method"
+	OptionHighlightSnippets      = "hl.snippets"
+	OptionHighlightFragSize      = "hl.fragsize"
+	OptionHighlightPreTag        = "hl.tag.pre"
+	OptionHighlightPostTag       = "hl.tag.post"
+	OptionHighlightWeightMatches = "hl.weightMatches"
+	HighlightMethodUnified       = "unified"
+	HighlightMethodOriginal      = "original"
+	HighlightMethodFastVector    = "fastVector"
+)
+
+// HighlightParams contains the available parameters to finetune highlighting. None
+// of the params is required, when no fields are given solr highlights the
+// default field.
+type HighlightParams struct {
+	Fields        []string
+	Query         string
+	Method        string
+	Snippets      int
+	FragSize      int
+	PreTag        string
+	PostTag       string
+	WeightMatches bool
+}
+
+// Highlight enables highlighting for the query, returning fragments of the matching
+// documents where the query terms are highlighted (check `Response.Highlighting`).
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/highlighting.html
+func (q *Query) Highlight(params *HighlightParams) {
+	q.params.Set(OptionHighlight, "true")
+	if params == nil {
+		return
+	}
+	if len(params.Fields) > 0 {
+		q.params.Set(OptionHighlightFields, strings.Join(params.Fields, ","))
+	}
+	if params.Query != "" {
+		q.params.Set(OptionHighlightQuery, params.Query)
+	}
+	if params.Method != "" {
+		q.params.Set(OptionHighlightMethod, params.Method)
+	}
+	if params.Snippets > 0 {
+		q.params.Set(OptionHighlightSnippets, strconv.Itoa(params.Snippets))
+	}
+	if params.FragSize > 0 {
+		q.params.Set(OptionHighlightFragSize, strconv.Itoa(params.FragSize))
+	}
+	if params.PreTag != "" {
+		q.params.Set(OptionHighlightPreTag, params.PreTag)
+	}
+	if params.PostTag != "" {
+		q.params.Set(OptionHighlightPostTag, params.PostTag)
+	}
+	if params.WeightMatches {
+		q.params.Set(OptionHighlightWeightMatches, "true")
+	}
+}
+
+// Highlighting contains the highlighted snippets of each document, mapped by
+// the document's id (uniqueKey field) and then by the highlighted field.
+type Highlighting map[string]map[string][]*HighlightSnippet
+
+// Get returns the snippets of the given field for the given document id.
+func (h Highlighting) Get(id, field string) []*HighlightSnippet {
+	return h[id][field]
+}
+
+// HighlightSnippet represents a single highlighted fragment. Usually solr
+// returns just the text of the snippet, but when the highlighter returns
+// structured snippets the offsets and score of each are captured too.
+type HighlightSnippet struct {
+	Text        string   `json:"text"`
+	StartOffset *int     `json:"startOffset"`
+	EndOffset   *int     `json:"endOffset"`
+	Score       *float64 `json:"score"`
+}
+
+func (s *HighlightSnippet) String() string {
+	return s.Text
+}
+
+// UnmarshalJSON implements the unmarshaler interface
+func (s *HighlightSnippet) UnmarshalJSON(b []byte) error {
+	var text string
+	err := json.Unmarshal(b, &text)
+	if err == nil {
+		s.Text = text
+		return nil
+	}
+
+	type snippet HighlightSnippet
+	var temp snippet
+	err = json.Unmarshal(b, &temp)
+	if err != nil {
+		return err
+	}
+	*s = HighlightSnippet(temp)
+	return nil
+}
@@ -0,0 +1,51 @@
+package solr
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestHighlight(t *testing.T) {
+	q := NewQuery(nil)
+	q.Highlight(&HighlightParams{
+		Fields:        []string{"name", "genre"},
+		Method:        HighlightMethodUnified,
+		Snippets:      2,
+		WeightMatches: true,
+	})
+	if q.params.Get("hl") != "true" {
+		t.Fatal("hl param not registered")
+	}
+	if q.params.Get("hl.fl") != "name,genre" {
+		t.Fatal("hl.fl param not registered")
+	}
+	if q.params.Get("hl.method") != HighlightMethodUnified {
+		t.Fatal("hl.method param not registered")
+	}
+	if q.params.Get("hl.snippets") != "2" {
+		t.Fatal("hl.snippets param not registered")
+	}
+	if q.params.Get("hl.weightMatches") != "true" {
+		t.Fatal("hl.weightMatches param not registered")
+	}
+}
+
+func TestHighlightingUnmarshal(t *testing.T) {
+	input := `{"highlighting":{"1":{"name":["<em>test</em> one",{"text":"<em>test</em> two","startOffset":4,"endOffset":12,"score":1.5}]}}}`
+	var res Response
+	err := json.Unmarshal([]byte(input), &res)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	snippets := res.Highlighting.Get("1", "name")
+	if len(snippets) != 2 {
+		t.Fatalf("expected 2 snippets but got %d", len(snippets))
+	}
+	if snippets[0].Text != "<em>test</em> one" || snippets[0].StartOffset != nil {
+		t.Fatalf("unexpected plain snippet: %+v", snippets[0])
+	}
+	if snippets[1].Text != "<em>test</em> two" || *snippets[1].StartOffset != 4 || *snippets[1].EndOffset != 12 || *snippets[1].Score != 1.5 {
+		t.Fatalf("unexpected structured snippet: %+v", snippets[1])
+	}
+}
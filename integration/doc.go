@@ -0,0 +1,7 @@
+// Package integration contains the integration tests of the solr package. They
+// run against a real solr server started with testcontainers, therefore docker
+// is required. They live in a separate module, so that their dependencies are
+// not required by the users of the solr package. Run them with:
+//
+//	cd integration && go test -tags integration ./...
+package integration
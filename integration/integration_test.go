@@ -0,0 +1,226 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mecenat/solr"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// Every test uses its own core, so that they do not depend on each
+// other. The cores are created when the container starts.
+const (
+	schemaCore    = "films_schema"
+	crudCore      = "films_crud"
+	coreAdminCore = "films_admin"
+)
+
+var integrationHost string
+
+func TestMain(m *testing.M) {
+	ctx := context.Background()
+
+	var cmd []string
+	for _, core := range []string{schemaCore, crudCore, coreAdminCore} {
+		cmd = append(cmd, "precreate-core "+core)
+	}
+	cmd = append(cmd, "exec solr-foreground")
+
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "solr:8.5",
+			ExposedPorts: []string{"8983/tcp"},
+			Cmd:          []string{"bash", "-c", strings.Join(cmd, "; ")},
+			WaitingFor:   wait.ForHTTP(fmt.Sprintf("/solr/%s/admin/ping", coreAdminCore)).WithPort("8983/tcp"),
+		},
+		Started: true,
+	}
+	container, err := testcontainers.GenericContainer(ctx, req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not start solr container: %v\n", err)
+		os.Exit(1)
+	}
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not get solr container host: %v\n", err)
+		os.Exit(1)
+	}
+	port, err := container.MappedPort(ctx, "8983")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not get solr container port: %v\n", err)
+		os.Exit(1)
+	}
+	integrationHost = fmt.Sprintf("http://%s:%s", host, port.Port())
+
+	code := m.Run()
+	_ = container.Terminate(ctx)
+	os.Exit(code)
+}
+
+func newIntegrationClient(t *testing.T, core string) solr.Client {
+	conn, err := solr.NewConnection(integrationHost, core, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	slr, err := solr.NewSingleClient(conn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return slr
+}
+
+func addFields(t *testing.T, core string, fields []*solr.Field) *solr.SchemaAPI {
+	ctx := context.Background()
+	sa, err := solr.NewSchemaAPI(ctx, integrationHost, core, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, fl := range fields {
+		_, err = sa.AddField(ctx, fl)
+		if err != nil {
+			t.Fatalf("unexpected error adding field %s: %v", fl.Name, err)
+		}
+	}
+	return sa
+}
+
+func TestIntegrationSchema(t *testing.T) {
+	ctx := context.Background()
+	sa := addFields(t, schemaCore, []*solr.Field{
+		{Name: "name", Type: "string"},
+		{Name: "genre", Type: "string"},
+	})
+
+	fl, err := sa.RetrieveField(ctx, "genre")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fl.Type != "string" {
+		t.Fatalf("expected field type string but got %s", fl.Type)
+	}
+
+	res, err := sa.RetrieveSchema(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Schema.UniqueKey != "id" {
+		t.Fatalf("expected uniqueKey id but got %s", res.Schema.UniqueKey)
+	}
+}
+
+func TestIntegrationCRUD(t *testing.T) {
+	ctx := context.Background()
+	addFields(t, crudCore, []*solr.Field{
+		{Name: "name", Type: "string"},
+		{Name: "genre", Type: "string"},
+		{Name: "year", Type: "pint"},
+	})
+	slr := newIntegrationClient(t, crudCore)
+
+	err := slr.Ping(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	docs := []map[string]interface{}{
+		{"id": "1", "name": "Alien", "genre": "scifi", "year": 1979},
+		{"id": "2", "name": "Aliens", "genre": "scifi", "year": 1986},
+		{"id": "3", "name": "Heat", "genre": "crime", "year": 1995},
+	}
+	_, err = slr.BatchCreate(ctx, docs, &solr.WriteOptions{Commit: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	res, err := slr.Get(ctx, "3", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Doc == nil || (*res.Doc)["name"] != "Heat" {
+		t.Fatalf("unexpected document: %v", res.Doc)
+	}
+
+	upd := solr.NewUpdateDocument("3")
+	upd.Set("name", "Heat (1995)")
+	_, err = slr.Update(ctx, upd, &solr.WriteOptions{Commit: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	res, err = slr.Get(ctx, "3", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if (*res.Doc)["name"] != "Heat (1995)" {
+		t.Fatalf("document not updated: %v", res.Doc)
+	}
+
+	q := solr.NewQuery(nil)
+	q.SetQuery("*:*")
+	q.AddFacet(&solr.Facet{Field: "genre"})
+	q.AddFacetPivot("genre,year", 1)
+	res, err = slr.Search(ctx, q)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Data.NumFound != 3 {
+		t.Fatalf("expected 3 documents but found %d", res.Data.NumFound)
+	}
+	if res.FacetCounts.Fields.Get("genre")["scifi"] != 2 {
+		t.Fatalf("unexpected facet counts: %v", res.FacetCounts.Fields.Get("genre"))
+	}
+	if len(res.FacetCounts.Pivot["genre,year"]) != 2 {
+		t.Fatalf("unexpected facet pivots: %v", res.FacetCounts.Pivot)
+	}
+
+	q = solr.NewQuery(nil)
+	q.SetQuery("*:*")
+	err = q.Group(&solr.GroupParams{Field: "genre", ShowGroupsNumber: true, Query: []string{"year:[1980 TO *]"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res, err = slr.Search(ctx, q)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Grouped.ByFieldOrFunc["genre"].NumberOfGroups != 2 {
+		t.Fatalf("unexpected field groups: %+v", res.Grouped.ByFieldOrFunc["genre"])
+	}
+	if res.Grouped.ByQuery["year:[1980 TO *]"].DocList.NumFound != 2 {
+		t.Fatalf("unexpected query groups: %+v", res.Grouped.ByQuery)
+	}
+
+	_, err = slr.DeleteByID(ctx, "1", &solr.WriteOptions{Commit: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	res, err = slr.Get(ctx, "1", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Doc != nil {
+		t.Fatalf("document not deleted: %v", res.Doc)
+	}
+}
+
+func TestIntegrationCoreAdmin(t *testing.T) {
+	ctx := context.Background()
+	ca, err := solr.NewCoreAdmin(ctx, integrationHost, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = ca.StatusOne(ctx, coreAdminCore)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
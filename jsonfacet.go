@@ -0,0 +1,116 @@
+package solr
+
+import (
+	"encoding/json"
+)
+
+// OptionJSONFacet is the param containing the JSON Facet API request
+const OptionJSONFacet = "json.facet"
+
+// JSONFacet sets the facets of the query using the JSON Facet API, which allows
+// nested sub-facets & aggregations, e.g.
+//
+//	q.JSONFacet(map[string]interface{}{
+//		"genres": map[string]interface{}{
+//			"type":  "terms",
+//			"field": "genre",
+//			"facet": map[string]interface{}{"avg_seen": "avg(seen_counter)"},
+//		},
+//	})
+//
+// The results are found in `Response.Facets`.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/json-facet-api.html
+func (q *Query) JSONFacet(facet map[string]interface{}) error {
+	b, err := json.Marshal(facet)
+	if err != nil {
+		return err
+	}
+	q.params.Set(OptionJSONFacet, string(b))
+	return nil
+}
+
+// JSONFacetResult contains the results of a JSON Facet API request. The same struct
+// represents the top level facets block, each named facet and each of the buckets
+// of a facet, as all of them may contain a count, nested facets & aggregations.
+type JSONFacetResult struct {
+	// Count is the number of documents in the domain of the facet or the bucket
+	Count int64
+	// Value is the value of the bucket (only on buckets)
+	Value interface{}
+	// Buckets contains the buckets of a terms, range or query facet
+	Buckets []*JSONFacetResult
+	// NumBuckets is the total number of buckets, when requested
+	NumBuckets int64
+	// Missing & AllBuckets are the special buckets, when requested
+	Missing    *JSONFacetResult
+	AllBuckets *JSONFacetResult
+	// Facets contains the nested facets mapped by their name
+	Facets map[string]*JSONFacetResult
+	// Stats contains the results of the aggregations mapped by their name
+	Stats map[string]interface{}
+}
+
+// Get returns the nested facet with the given name, or nil if it does not exist.
+func (r *JSONFacetResult) Get(name string) *JSONFacetResult {
+	if r == nil {
+		return nil
+	}
+	return r.Facets[name]
+}
+
+// Stat returns the result of the named aggregation as a number, if possible.
+func (r *JSONFacetResult) Stat(name string) (float64, bool) {
+	if r == nil {
+		return 0, false
+	}
+	return toFloat64(r.Stats[name])
+}
+
+// UnmarshalJSON implements the unmarshaler interface. Solr mixes the reserved keys
+// (count, val, buckets etc.) with the user defined names of the nested facets and
+// aggregations, which are told apart by their value being an object or not.
+func (r *JSONFacetResult) UnmarshalJSON(b []byte) error {
+	var m map[string]json.RawMessage
+	err := json.Unmarshal(b, &m)
+	if err != nil {
+		return err
+	}
+
+	for key, raw := range m {
+		switch key {
+		case "count":
+			err = json.Unmarshal(raw, &r.Count)
+		case "val":
+			err = json.Unmarshal(raw, &r.Value)
+		case "buckets":
+			err = json.Unmarshal(raw, &r.Buckets)
+		case "numBuckets":
+			err = json.Unmarshal(raw, &r.NumBuckets)
+		case "missing":
+			err = json.Unmarshal(raw, &r.Missing)
+		case "allBuckets":
+			err = json.Unmarshal(raw, &r.AllBuckets)
+		default:
+			if isJSON(raw) == nil {
+				var f JSONFacetResult
+				err = json.Unmarshal(raw, &f)
+				if r.Facets == nil {
+					r.Facets = make(map[string]*JSONFacetResult)
+				}
+				r.Facets[key] = &f
+				break
+			}
+			var v interface{}
+			err = json.Unmarshal(raw, &v)
+			if r.Stats == nil {
+				r.Stats = make(map[string]interface{})
+			}
+			r.Stats[key] = v
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package solr
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONFacet(t *testing.T) {
+	q := NewQuery(nil)
+	err := q.JSONFacet(map[string]interface{}{
+		"genres": map[string]interface{}{"type": "terms", "field": "genre"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"genres":{"field":"genre","type":"terms"}}`
+	if q.params.Get("json.facet") != expected {
+		t.Fatalf("expected %s but got %s", expected, q.params.Get("json.facet"))
+	}
+}
+
+func TestJSONFacetResultUnmarshal(t *testing.T) {
+	input := `{"facets":{"count":10,"max_year":2020,
+		"genres":{"numBuckets":2,"buckets":[
+			{"val":"horror","count":6,"avg_seen":3.5,"directors":{"buckets":[{"val":"carpenter","count":2}]}},
+			{"val":"comedy","count":4,"avg_seen":1.0}],
+			"missing":{"count":1}}}}`
+	var res Response
+	err := json.Unmarshal([]byte(input), &res)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	f := res.Facets
+	if f.Count != 10 {
+		t.Fatalf("unexpected count: %d", f.Count)
+	}
+	if v, ok := f.Stat("max_year"); !ok || v != 2020 {
+		t.Fatalf("unexpected max_year: %v", v)
+	}
+
+	genres := f.Get("genres")
+	if genres.NumBuckets != 2 || len(genres.Buckets) != 2 || genres.Missing.Count != 1 {
+		t.Fatalf("unexpected genres facet: %+v", genres)
+	}
+	horror := genres.Buckets[0]
+	if horror.Value != "horror" || horror.Count != 6 {
+		t.Fatalf("unexpected bucket: %+v", horror)
+	}
+	if v, ok := horror.Stat("avg_seen"); !ok || v != 3.5 {
+		t.Fatalf("unexpected avg_seen: %v", v)
+	}
+	if horror.Get("directors").Buckets[0].Value != "carpenter" {
+		t.Fatalf("unexpected nested facet: %+v", horror.Get("directors"))
+	}
+	if f.Get("unknown") != nil || f.Get("unknown").Get("other") != nil {
+		t.Fatal("expected nil for unknown facets")
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
@@ -14,13 +16,14 @@ import (
 // ManagedResponse represents the response from solr's managed resources API.
 // Header and Error (if there is any) will always be populated. The rest
 // are helpers on specific cases. Currently supported cases are when
-// requesting for a list of all managed resources, and for
-// a managed synonyms list.
+// requesting for a list of all managed resources, for
+// a managed synonyms list and for a managed stopwords list.
 type ManagedResponse struct {
 	Header    *ResponseHeader    `json:"responseHeader"`
 	Error     *ResponseError     `json:"error"`
 	Resources []*ManagedResource `json:"managedResources"`
 	Synonyms  *SynonymMappings   `json:"synonymMappings"`
+	Stopwords *StopwordsList     `json:"wordSet"`
 	RawMap    map[string]interface{}
 }
 
@@ -96,6 +99,20 @@ func (r *ManagedResponse) UnmarshalJSON(b []byte) error {
 		r.Synonyms = &syn
 	}
 
+	wordInf, ok := m["wordSet"]
+	if ok {
+		wordBytes, err := interfaceToBytes(wordInf)
+		if err != nil {
+			return err
+		}
+		var words StopwordsList
+		err = json.Unmarshal(wordBytes, &words)
+		if err != nil {
+			return err
+		}
+		r.Stopwords = &words
+	}
+
 	return nil
 }
 
@@ -120,6 +137,20 @@ type SynonymInitArgs struct {
 	IgnoreCase bool `json:"ignoreCase"`
 }
 
+// StopwordsList is a helper struct for navigating a stopwords managed list.
+type StopwordsList struct {
+	InitArgs    *StopwordInitArgs `json:"initArgs"`
+	InitOn      time.Time         `json:"initializedOn"`
+	UpdatedOn   time.Time         `json:"updatedSinceInit"`
+	ManagedList []string          `json:"managedList"`
+}
+
+// StopwordInitArgs are the initialization arguments for a stopwords
+// managed list.
+type StopwordInitArgs struct {
+	IgnoreCase bool `json:"ignoreCase"`
+}
+
 // ManagedAPI contains a connection to solr
 type ManagedAPI struct {
 	conn     *Connection
@@ -156,6 +187,13 @@ func (m *ManagedAPI) SetBasicAuth(username, password string) {
 	m.conn.Password = password
 }
 
+// SetOnRequest sets a hook that is called after each request with information
+// about it, e.g. in order to record metrics or tracing spans (check
+// `Connection.OnRequest`).
+func (m *ManagedAPI) SetOnRequest(hook func(info RequestInfo)) {
+	m.conn.OnRequest = hook
+}
+
 func (m *ManagedAPI) request(ctx context.Context, method, url string, body []byte) (*ManagedResponse, error) {
 	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
 	if err != nil {
@@ -164,22 +202,36 @@ func (m *ManagedAPI) request(ctx context.Context, method, url string, body []byt
 
 	req.Header.Add("Content-Type", "application/json")
 
-	if m.conn.Username != "" && m.conn.Password != "" {
-		req.SetBasicAuth(m.conn.Username, m.conn.Password)
+	if username, password := credentials(ctx, m.conn.Username, m.conn.Password); username != "" && password != "" {
+		req.SetBasicAuth(username, password)
 	}
 
+	start := time.Now()
 	res, err := m.conn.httpClient.Do(req.WithContext(ctx))
 	if err != nil {
+		notifyRequest(m.conn.OnRequest, method, url, start, 0, err)
 		return nil, err
 	}
-
-	var r ManagedResponse
 	defer res.Body.Close()
 
-	err = json.NewDecoder(res.Body).Decode(&r)
+	r, err := decodeManagedResponse(res.StatusCode, res.Body)
+	notifyRequest(m.conn.OnRequest, method, url, start, res.StatusCode, err)
+	return r, err
+}
+
+func decodeManagedResponse(statusCode int, body io.Reader) (*ManagedResponse, error) {
+	var r ManagedResponse
+	resBody, err := io.ReadAll(body)
 	if err != nil {
 		return nil, err
 	}
+	err = json.Unmarshal(resBody, &r)
+	if err != nil {
+		if statusCode >= http.StatusBadRequest {
+			return nil, &HTTPError{StatusCode: statusCode, Body: string(resBody)}
+		}
+		return nil, err
+	}
 
 	if r.Error != nil {
 		return &r, r.Error
@@ -200,6 +252,25 @@ func (m *ManagedAPI) RetrieveResource(ctx context.Context, path string) (*Manage
 	return m.request(ctx, http.MethodGet, m.formatURL(path), nil)
 }
 
+// ResourceExists reports whether the specified resource exists, interpreting a not found
+// response as false. This allows e.g. provisioning scripts to skip the creation or
+// deletion of resources idempotently.
+func (m *ManagedAPI) ResourceExists(ctx context.Context, path string) (bool, error) {
+	_, err := m.RetrieveResource(ctx, path)
+	if err == nil {
+		return true, nil
+	}
+	var solrErr *ResponseError
+	if errors.As(err, &solrErr) && solrErr.Code == http.StatusNotFound {
+		return false, nil
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	return false, err
+}
+
 // UpsertResource updates the specified resource. Requires the path to the resource and
 // the resource to be created/updated
 func (m *ManagedAPI) UpsertResource(ctx context.Context, path string, data interface{}) (*ManagedResponse, error) {
@@ -287,3 +358,102 @@ func (m *ManagedAPI) SynonymDelete(ctx context.Context, listName string, synonym
 	path := fmt.Sprintf("/analysis/synonyms/%s/%s", listName, synonym)
 	return m.DeleteResource(ctx, path)
 }
+
+// StopwordsList returns all the stopwords in the specified list.
+func (m *ManagedAPI) StopwordsList(ctx context.Context, listName string) (*ManagedResponse, error) {
+	path := "/analysis/stopwords/" + listName
+	return m.RetrieveResource(ctx, path)
+}
+
+// StopwordsGet checks whether the specified word exists in the specified list. If
+// it does not, solr responds with a not found error.
+func (m *ManagedAPI) StopwordsGet(ctx context.Context, listName string, word string) (*ManagedResponse, error) {
+	path := fmt.Sprintf("/analysis/stopwords/%s/%s", listName, word)
+	return m.RetrieveResource(ctx, path)
+}
+
+// StopwordsAdd adds the provided words in the specified list.
+func (m *ManagedAPI) StopwordsAdd(ctx context.Context, listName string, words []string) (*ManagedResponse, error) {
+	path := "/analysis/stopwords/" + listName
+	return m.UpsertResource(ctx, path, words)
+}
+
+// StopwordsDelete removes the specified word from the specified stopwords list.
+func (m *ManagedAPI) StopwordsDelete(ctx context.Context, listName string, word string) (*ManagedResponse, error) {
+	path := fmt.Sprintf("/analysis/stopwords/%s/%s", listName, word)
+	return m.DeleteResource(ctx, path)
+}
+
+// ReloadRequired reports whether any of the managed resources of the core has been updated
+// since it was initialized, in which case the core must be reloaded for the changes
+// to take effect. Solr keeps the update time of a resource after a reload, therefore
+// it is compared with the initialization time instead of merely being checked for.
+// It retrieves every managed resource, therefore it should not be used in hot paths.
+func (m *ManagedAPI) ReloadRequired(ctx context.Context) (bool, error) {
+	res, err := m.RestManager(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, resource := range res.Resources {
+		path := strings.TrimPrefix(resource.ID, "/schema")
+		resRes, err := m.RetrieveResource(ctx, path)
+		if err != nil {
+			return false, err
+		}
+		if hasUpdatedSinceInit(resRes.RawMap) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// hasUpdatedSinceInit checks whether the given map or any of the maps nested
+// in it have an updatedSinceInit time later than their initializedOn time. If
+// the initialization time is missing or cannot be parsed, the presence of the
+// update time is enough.
+func hasUpdatedSinceInit(m map[string]interface{}) bool {
+	if updated, ok := m["updatedSinceInit"].(string); ok {
+		updatedOn, err := time.Parse(time.RFC3339Nano, updated)
+		if err != nil {
+			return true
+		}
+		initOn, err := time.Parse(time.RFC3339Nano, fmt.Sprint(m["initializedOn"]))
+		if err != nil || updatedOn.After(initOn) {
+			return true
+		}
+	}
+	for _, val := range m {
+		nested, ok := val.(map[string]interface{})
+		if ok && hasUpdatedSinceInit(nested) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReloadCore reloads the core of the managed resources, which is required for the changes made
+// to them to take effect. A core admin is created for this purpose using the host, http
+// client, credentials & request hook of the managed API (check `ApplyAndReload` for
+// using a different one).
+func (m *ManagedAPI) ReloadCore(ctx context.Context) (*CoreAdminResponse, error) {
+	ca, err := NewCoreAdmin(ctx, m.conn.Host, m.conn.httpClient)
+	if err != nil {
+		return nil, err
+	}
+	ca.SetBasicAuth(m.conn.Username, m.conn.Password)
+	ca.SetOnRequest(m.conn.OnRequest)
+	return ca.Reload(ctx, m.conn.Core)
+}
+
+// ApplyAndReload calls the provided edit function, which should make changes to the managed
+// resources (e.g. add synonyms), and then reloads the given core using the provided
+// core admin, so that the changes take effect.
+func (m *ManagedAPI) ApplyAndReload(ctx context.Context, ca *CoreAdmin, core string, edit func(ctx context.Context, m *ManagedAPI) error) error {
+	err := edit(ctx, m)
+	if err != nil {
+		return err
+	}
+	_, err = ca.Reload(ctx, core)
+	return err
+}
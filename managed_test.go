@@ -0,0 +1,268 @@
+package solr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mecenat/solr/solrtest"
+)
+
+func TestHasUpdatedSinceInit(t *testing.T) {
+	m := map[string]interface{}{
+		"responseHeader": map[string]interface{}{"status": 0},
+		"synonymMappings": map[string]interface{}{
+			"initializedOn": "2020-10-10T10:00:00.000Z",
+		},
+	}
+	if hasUpdatedSinceInit(m) {
+		t.Fatal("resource should not require a reload")
+	}
+
+	m["synonymMappings"].(map[string]interface{})["updatedSinceInit"] = "2020-10-10T11:00:00.000Z"
+	if !hasUpdatedSinceInit(m) {
+		t.Fatal("resource should require a reload")
+	}
+
+	m["synonymMappings"].(map[string]interface{})["initializedOn"] = "2020-10-10T12:00:00.000Z"
+	if hasUpdatedSinceInit(m) {
+		t.Fatal("resource reloaded after its last update should not require a reload")
+	}
+}
+
+func TestReloadRequired(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	srv.Handle("/solr/films/schema/managed", http.StatusOK, `{"responseHeader":{"status":0},"managedResources":[
+		{"resourceId":"/schema/analysis/stopwords/english","class":"org.apache.solr.rest.schema.analysis.ManagedWordSetResource","numObservers":"1"},
+		{"resourceId":"/schema/analysis/synonyms/english","class":"org.apache.solr.rest.schema.analysis.ManagedSynonymGraphFilterFactory$SynonymManager","numObservers":"1"}]}`)
+	srv.Handle("/solr/films/schema/analysis/stopwords/english", http.StatusOK, `{"responseHeader":{"status":0},
+		"wordSet":{"initArgs":{"ignoreCase":true},"initializedOn":"2020-10-10T12:00:00.000Z",
+			"updatedSinceInit":"2020-10-10T11:00:00.000Z","managedList":["a","an","the"]}}`)
+	srv.Handle("/solr/films/schema/analysis/synonyms/english", http.StatusOK, `{"responseHeader":{"status":0},
+		"synonymMappings":{"initArgs":{"ignoreCase":true},"initializedOn":"2020-10-10T12:00:00.000Z",
+			"managedMap":{"tv":["television"]}}}`)
+
+	m, err := NewManagedAPI(context.Background(), srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	required, err := m.ReloadRequired(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if required {
+		t.Fatal("expected no reload to be required after the resources were reloaded")
+	}
+
+	srv.Handle("/solr/films/schema/analysis/synonyms/english", http.StatusOK, `{"responseHeader":{"status":0},
+		"synonymMappings":{"initArgs":{"ignoreCase":true},"initializedOn":"2020-10-10T12:00:00.000Z",
+			"updatedSinceInit":"2020-10-10T13:00:00.000Z","managedMap":{"tv":["television"]}}}`)
+	required, err = m.ReloadRequired(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !required {
+		t.Fatal("expected a reload to be required after an edit")
+	}
+}
+
+func TestApplyAndReload(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	m, err := NewManagedAPI(ctx, srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := NewCoreAdmin(ctx, srv.URL, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.ApplyAndReload(ctx, ca, "films", func(ctx context.Context, m *ManagedAPI) error {
+		_, err := m.StopwordsAdd(ctx, "english", []string{"of"})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reqs := srv.Requests()
+	if len(reqs) != 2 {
+		t.Fatalf("expected 2 requests but got %d", len(reqs))
+	}
+	if reqs[0].Method != http.MethodPut || reqs[0].Path != "/solr/films/schema/analysis/stopwords/english" {
+		t.Fatalf("unexpected edit request: %s %s", reqs[0].Method, reqs[0].Path)
+	}
+	if reqs[1].Path != "/solr/admin/cores" {
+		t.Fatalf("unexpected reload path: %s", reqs[1].Path)
+	}
+	solrtest.AssertParam(t, reqs[1], "action", "RELOAD")
+	solrtest.AssertParam(t, reqs[1], "core", "films")
+
+	srv.Reset()
+	editErr := errors.New("edit failed")
+	err = m.ApplyAndReload(ctx, ca, "films", func(ctx context.Context, m *ManagedAPI) error {
+		return editErr
+	})
+	if err != editErr {
+		t.Fatalf("expected %v but got %v", editErr, err)
+	}
+	if len(srv.Requests()) != 0 {
+		t.Fatalf("expected no reload after a failed edit but got %d requests", len(srv.Requests()))
+	}
+}
+
+func TestStopwords(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	srv.Handle("/solr/films/schema/analysis/stopwords/english", http.StatusOK, `{"responseHeader":{"status":0,"QTime":1},
+		"wordSet":{"initArgs":{"ignoreCase":true},"initializedOn":"2020-10-10T10:00:00.000Z",
+			"updatedSinceInit":"2020-10-10T11:00:00.000Z","managedList":["a","an","the"]}}`)
+
+	m, err := NewManagedAPI(context.Background(), srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := m.StopwordsList(context.Background(), "english")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Stopwords == nil || !res.Stopwords.InitArgs.IgnoreCase || len(res.Stopwords.ManagedList) != 3 {
+		t.Fatalf("unexpected stopwords: %+v", res.Stopwords)
+	}
+	if res.Stopwords.UpdatedOn.IsZero() {
+		t.Fatal("expected the update time to be parsed")
+	}
+
+	_, err = m.StopwordsAdd(context.Background(), "english", []string{"of", "to"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req := srv.LastRequest()
+	if req.Method != http.MethodPut {
+		t.Fatalf("expected a PUT request but got %s", req.Method)
+	}
+	solrtest.AssertJSONBody(t, req, `["of","to"]`)
+
+	_, err = m.StopwordsDelete(context.Background(), "english", "the")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req = srv.LastRequest()
+	if req.Method != http.MethodDelete || req.Path != "/solr/films/schema/analysis/stopwords/english/the" {
+		t.Fatalf("unexpected request: %s %s", req.Method, req.Path)
+	}
+}
+
+func TestManagedResponseStopwords(t *testing.T) {
+	payload := `{
+  "responseHeader":{
+    "status":0,
+    "QTime":1},
+  "wordSet":{
+    "initArgs":{"ignoreCase":true},
+    "initializedOn":"2014-03-28T20:53:53.058Z",
+    "managedList":[
+      "a",
+      "an",
+      "and",
+      "are"]}}`
+
+	var res ManagedResponse
+	err := json.Unmarshal([]byte(payload), &res)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Synonyms != nil {
+		t.Fatal("synonyms should not be populated for a stopwords list")
+	}
+	words := res.Stopwords
+	if words == nil || words.InitArgs == nil || !words.InitArgs.IgnoreCase {
+		t.Fatalf("unexpected stopwords: %+v", words)
+	}
+	if !words.InitOn.Equal(time.Date(2014, 3, 28, 20, 53, 53, 58000000, time.UTC)) {
+		t.Fatalf("unexpected initialization time: %s", words.InitOn)
+	}
+	if !words.UpdatedOn.IsZero() {
+		t.Fatalf("expected no update time but got %s", words.UpdatedOn)
+	}
+	if strings.Join(words.ManagedList, ",") != "a,an,and,are" {
+		t.Fatalf("unexpected managed list: %v", words.ManagedList)
+	}
+}
+
+func TestReloadCore(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+
+	m, err := NewManagedAPI(context.Background(), srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.ReloadCore(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req := srv.LastRequest()
+	if req.Path != "/solr/admin/cores" {
+		t.Fatalf("unexpected path: %s", req.Path)
+	}
+	solrtest.AssertParam(t, req, "action", "RELOAD")
+	solrtest.AssertParam(t, req, "core", "films")
+
+	var infos []RequestInfo
+	m.SetOnRequest(func(info RequestInfo) {
+		infos = append(infos, info)
+	})
+	_, err = m.ReloadCore(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(infos) != 1 || !strings.Contains(infos[0].URL, "/solr/admin/cores") {
+		t.Fatalf("expected the reload to notify the request hook but got %+v", infos)
+	}
+}
+
+func TestResourceExists(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	srv.Handle("/solr/films/schema/analysis/stopwords/english", http.StatusOK, `{"responseHeader":{"status":0},
+		"wordSet":{"initArgs":{"ignoreCase":true},"managedList":["a","an","the"]}}`)
+	srv.Handle("/solr/films/schema/analysis/stopwords/german", http.StatusNotFound, `{"responseHeader":{"status":404},
+		"error":{"msg":"No REST managed resource registered for path /schema/analysis/stopwords/german","code":404}}`)
+	srv.Handle("/solr/films/schema/analysis/stopwords/french", http.StatusNotFound, `<html><body><h2>HTTP ERROR 404</h2></body></html>`)
+	srv.Handle("/solr/films/schema/analysis/stopwords/italian", http.StatusInternalServerError, `{"responseHeader":{"status":500},
+		"error":{"msg":"boom","code":500}}`)
+
+	m, err := NewManagedAPI(context.Background(), srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	exists, err := m.ResourceExists(ctx, "/analysis/stopwords/english")
+	if err != nil || !exists {
+		t.Fatalf("expected the resource to exist but got %v, %v", exists, err)
+	}
+	exists, err = m.ResourceExists(ctx, "/analysis/stopwords/german")
+	if err != nil || exists {
+		t.Fatalf("expected the resource not to exist but got %v, %v", exists, err)
+	}
+	exists, err = m.ResourceExists(ctx, "/analysis/stopwords/french")
+	if err != nil || exists {
+		t.Fatalf("expected the resource not to exist but got %v, %v", exists, err)
+	}
+	_, err = m.ResourceExists(ctx, "/analysis/stopwords/italian")
+	if err == nil {
+		t.Fatal("expected error but got none")
+	}
+}
@@ -2,82 +2,147 @@ package solr
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync/atomic"
 )
 
 // PRClient implements the solr interface in Primary - Replica server
 // architecture. It contains a connection to a Primary server used
-// for writing data, and a connection to a Replica server used
-// for reading data.
+// for writing data, and connections to one or more Replica servers
+// used for reading data. ReplicaPath is the path of the first one.
 type PRClient struct {
 	primary     connection
-	replica     connection
+	replicas    []connection
+	next        uint32
 	PrimaryPath string
 	ReplicaPath string
+	pingPath    string
 }
 
 // NewPrimaryReplicaClient returns two connections from the provided host and cores, one for the primary
 // server and another for the replica. By default it is assumed that the primary server is used for
 // writing data, and the replica server for reading data.
 func NewPrimaryReplicaClient(primaryConn, replicaConn connection) (Client, error) {
-	pBasePath := primaryConn.formatBasePath()
-	rBasePath := replicaConn.formatBasePath()
+	return NewMultiReplicaClient(primaryConn, replicaConn)
+}
+
+// NewMultiReplicaClient returns a client that writes data to the primary server and spreads the reads
+// across the provided replicas in a round-robin fashion. In case a replica fails to respond, the
+// read is retried on the next one until all of them have been tried. Errors returned by solr
+// itself (e.g. an invalid query) are returned as is.
+func NewMultiReplicaClient(primaryConn connection, replicaConns ...connection) (Client, error) {
+	if len(replicaConns) == 0 {
+		return nil, ErrNoReplicas
+	}
 	return &PRClient{
 		primary:     primaryConn,
-		replica:     replicaConn,
-		PrimaryPath: pBasePath,
-		ReplicaPath: rBasePath,
+		replicas:    replicaConns,
+		PrimaryPath: primaryConn.formatBasePath(),
+		ReplicaPath: replicaConns[0].formatBasePath(),
 	}, nil
 }
 
 // SetBasicAuth sets auth credentials if needed.
 func (c *PRClient) SetBasicAuth(username, password string) {
 	c.primary.setBasicAuth(username, password)
-	c.replica.setBasicAuth(username, password)
+	for _, replica := range c.replicas {
+		replica.setBasicAuth(username, password)
+	}
 }
 
-func (c *PRClient) formatPrimaryURL(path string, query string) string {
-	if query != "" {
-		return c.PrimaryPath + path + "?" + query
+// SetKeepRaw sets whether the raw response body should be kept.
+func (c *PRClient) SetKeepRaw(keep bool) {
+	c.primary.setKeepRaw(keep)
+	for _, replica := range c.replicas {
+		replica.setKeepRaw(keep)
 	}
-	return c.PrimaryPath + path
 }
 
-func (c *PRClient) formatReplicaURL(path string, query string) string {
-	if query != "" {
-		return c.ReplicaPath + path + "?" + query
+// SetPingPath sets the path of the ping request handler.
+func (c *PRClient) SetPingPath(path string) {
+	c.pingPath = path
+}
+
+func (c *PRClient) formatPrimaryURL(path string, query string) string {
+	return formatURL(c.PrimaryPath, path, query)
+}
+
+// read sends the read request to the next replica, failing over to the following
+// ones when the replica cannot be reached or responds without a solr error. The
+// response of the last replica tried is returned along with its error.
+func (c *PRClient) read(ctx context.Context, path string, query string) (*Response, error) {
+	start := atomic.AddUint32(&c.next, 1) - 1
+	var res *Response
+	var err error
+	for i := range c.replicas {
+		replica := c.replicas[(int(start)+i)%len(c.replicas)]
+		res, err = read(ctx, replica, formatURL(replica.formatBasePath(), path, query))
+		if err == nil {
+			return res, nil
+		}
+		var solrErr *ResponseError
+		if errors.As(err, &solrErr) || ctx.Err() != nil {
+			return res, err
+		}
 	}
-	return c.ReplicaPath + path
+	return res, err
 }
 
 // Ping tests the connectivity of both servers
 func (c *PRClient) Ping(ctx context.Context) error {
-	url := c.formatPrimaryURL("/admin/ping", "")
-	res, err := c.primary.request(ctx, http.MethodGet, url, nil)
+	url := c.formatPrimaryURL(pingPath(c.pingPath), "")
+	res, err := c.primary.request(ctx, http.MethodGet, url, ContentTypeJSON, nil)
 	if err != nil {
 		return err
 	}
 	if res.Status != nil && *res.Status != "OK" {
 		return fmt.Errorf("error pinging primary server, status: %s", *res.Status)
 	}
-	url = c.formatReplicaURL("/admin/ping", "")
-	res, err = c.replica.request(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return err
-	}
-	if res.Status != nil && *res.Status != "OK" {
-		return fmt.Errorf("error pinging replica server, status: %s", *res.Status)
+	for _, replica := range c.replicas {
+		url = formatURL(replica.formatBasePath(), pingPath(c.pingPath), "")
+		res, err = replica.request(ctx, http.MethodGet, url, ContentTypeJSON, nil)
+		if err != nil {
+			return err
+		}
+		if res.Status != nil && *res.Status != "OK" {
+			return fmt.Errorf("error pinging replica server, status: %s", *res.Status)
+		}
 	}
 	return nil
 }
 
 // Search ...
 func (c *PRClient) Search(ctx context.Context, q *Query) (*Response, error) {
-	url := c.formatReplicaURL("/select", q.String())
-	return read(ctx, c.replica, url)
+	return c.read(ctx, "/select", q.String())
+}
+
+// SearchHandler performs the query against the provided request handler of the replicas
+// instead of `/select`.
+func (c *PRClient) SearchHandler(ctx context.Context, handler string, q *Query) (*Response, error) {
+	return c.read(ctx, formatHandlerPath(handler), q.String())
+}
+
+// Suggest ...
+func (c *PRClient) Suggest(ctx context.Context, params *SuggestParams) (*Response, error) {
+	vals, err := params.format()
+	if err != nil {
+		return nil, err
+	}
+	return c.read(ctx, params.handlerPath(), vals.Encode())
+}
+
+// Terms ...
+func (c *PRClient) Terms(ctx context.Context, params *TermsParams) (*Response, error) {
+	vals, err := params.format()
+	if err != nil {
+		return nil, err
+	}
+	return c.read(ctx, params.handlerPath(), vals.Encode())
 }
 
 // Get ...
@@ -87,8 +152,7 @@ func (c *PRClient) Get(ctx context.Context, id, filter string) (*Response, error
 	if filter != "" {
 		vals.Set("fq", filter)
 	}
-	url := c.formatReplicaURL("/get", vals.Encode())
-	return read(ctx, c.replica, url)
+	return c.read(ctx, "/get", vals.Encode())
 }
 
 // BatchGet ...
@@ -98,26 +162,65 @@ func (c *PRClient) BatchGet(ctx context.Context, ids []string, filter string) (*
 	if filter != "" {
 		vals.Set("fq", filter)
 	}
-	url := c.formatReplicaURL("/get", vals.Encode())
-	return read(ctx, c.replica, url)
+	return c.read(ctx, "/get", vals.Encode())
+}
+
+// MaxVersion returns the highest absolute `_version_` found in the update log of the primary
+// server, as deletions are logged with a negative version. ErrNoVersions is returned when the
+// update log is empty.
+func (c *PRClient) MaxVersion(ctx context.Context) (int64, error) {
+	url := c.formatPrimaryURL("/get", "getVersions=1")
+	return maxVersion(ctx, c.primary, url)
 }
 
 // Create ...
 func (c *PRClient) Create(ctx context.Context, item interface{}, opts *WriteOptions) (*Response, error) {
-	url := c.formatPrimaryURL("/update/json/docs", opts.formatQueryFromOpts().Encode())
-	return create(ctx, c.primary, url, item)
+	url := c.formatPrimaryURL(opts.handlerPath("/update/json/docs"), opts.formatQueryFromOpts().Encode())
+	return create(ctx, c.primary, url, item, opts)
 }
 
 // BatchCreate ...
 func (c *PRClient) BatchCreate(ctx context.Context, items interface{}, opts *WriteOptions) (*Response, error) {
-	url := c.formatPrimaryURL("/update", opts.formatQueryFromOpts().Encode())
-	return batchCreate(ctx, c.primary, url, items)
+	url := c.formatPrimaryURL(opts.handlerPath("/update"), opts.formatQueryFromOpts().Encode())
+	return batchCreate(ctx, c.primary, url, items, opts)
+}
+
+// BatchCreateStream ...
+func (c *PRClient) BatchCreateStream(ctx context.Context, r io.Reader, opts *WriteOptions) (*Response, error) {
+	url := c.formatPrimaryURL(opts.handlerPath("/update/json/docs"), opts.formatQueryFromOpts().Encode())
+	return batchCreateStream(ctx, c.primary, url, r)
+}
+
+// UploadCSV ...
+func (c *PRClient) UploadCSV(ctx context.Context, data []byte, opts *WriteOptions) (*Response, error) {
+	url := c.formatPrimaryURL(opts.handlerPath("/update"), opts.formatQueryFromOpts().Encode())
+	return upload(ctx, c.primary, url, ContentTypeCSV, data)
+}
+
+// UploadXML ...
+func (c *PRClient) UploadXML(ctx context.Context, data []byte, opts *WriteOptions) (*Response, error) {
+	url := c.formatPrimaryURL(opts.handlerPath("/update"), opts.formatQueryFromOpts().Encode())
+	return upload(ctx, c.primary, url, ContentTypeXML, data)
+}
+
+// ExtractAndIndex ...
+func (c *PRClient) ExtractAndIndex(ctx context.Context, file io.Reader, contentType string, literals map[string]string, opts *ExtractOptions) (*Response, error) {
+	url := c.formatPrimaryURL(opts.writeOptions().handlerPath("/update/extract"), formatExtractQuery(literals, opts).Encode())
+	return extract(ctx, c.primary, url, contentType, file)
 }
 
 // Update ...
 func (c *PRClient) Update(ctx context.Context, item *UpdatedFields, opts *WriteOptions) (*Response, error) {
-	url := c.formatPrimaryURL("/update", opts.formatQueryFromOpts().Encode())
-	return update(ctx, c.primary, url, item)
+	url := c.formatPrimaryURL(opts.handlerPath("/update"), opts.formatQueryFromOpts().Encode())
+	return update(ctx, c.primary, url, item, opts)
+}
+
+// Upsert sets every provided field on the document with the given id via atomic `set`
+// updates, creating the document if it does not exist. A `_version_` entry in the fields
+// is passed through as is instead of being wrapped in a `set`, enabling optimistic
+// concurrency (check `UpdatedFields.SetVersion`).
+func (c *PRClient) Upsert(ctx context.Context, id string, fields map[string]interface{}, opts *WriteOptions) (*Response, error) {
+	return c.Update(ctx, newUpsertDocument(id, fields), opts)
 }
 
 // Commit ...
@@ -140,13 +243,21 @@ func (c *PRClient) Optimize(ctx context.Context, opts *OptimizeOptions) (*Respon
 
 // DeleteByID ...
 func (c *PRClient) DeleteByID(ctx context.Context, id string, opts *WriteOptions) (*Response, error) {
-	url := c.formatPrimaryURL("/update", opts.formatQueryFromOpts().Encode())
+	url := c.formatPrimaryURL(opts.handlerPath("/update"), opts.formatQueryFromOpts().Encode())
 	return delete(ctx, c.primary, url, formatDeleteByID(id))
 }
 
+// DeleteByIDs ...
+func (c *PRClient) DeleteByIDs(ctx context.Context, ids []string, opts *WriteOptions) (*Response, error) {
+	path := opts.handlerPath("/update")
+	chunkURL := c.formatPrimaryURL(path, opts.formatChunkQueryFromOpts().Encode())
+	lastURL := c.formatPrimaryURL(path, opts.formatQueryFromOpts().Encode())
+	return deleteByIDs(ctx, c.primary, chunkURL, lastURL, ids, opts.chunkSize())
+}
+
 // DeleteByQuery ...
 func (c *PRClient) DeleteByQuery(ctx context.Context, query string, opts *WriteOptions) (*Response, error) {
-	url := c.formatPrimaryURL("/update", opts.formatQueryFromOpts().Encode())
+	url := c.formatPrimaryURL(opts.handlerPath("/update"), opts.formatQueryFromOpts().Encode())
 	return delete(ctx, c.primary, url, formatDeleteByQuery(query))
 }
 
@@ -157,6 +268,16 @@ func (c *PRClient) Clear(ctx context.Context) (*Response, error) {
 
 // CustomUpdate ...
 func (c *PRClient) CustomUpdate(ctx context.Context, item *UpdateBuilder, opts *WriteOptions) (*Response, error) {
-	url := c.formatPrimaryURL("/update", opts.formatQueryFromOpts().Encode())
+	url := c.formatPrimaryURL(opts.handlerPath("/update"), opts.formatQueryFromOpts().Encode())
 	return customUpdate(ctx, c.primary, url, item)
 }
+
+// DataImport ...
+func (c *PRClient) DataImport(ctx context.Context, handler string, command DataImportCommand, params map[string]string) (*Response, error) {
+	query, err := formatDataImportQuery(command, params)
+	if err != nil {
+		return nil, err
+	}
+	url := c.formatPrimaryURL(formatHandlerPath(handler), query)
+	return read(ctx, c.primary, url)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -14,6 +15,9 @@ const (
 	OptionDefType                      = "defType"
 	OptionQ                            = "q"
 	OptionQOperation                   = "q.op"
+	OptionDefaultField                 = "df"
+	OptionSplitOnWhitespace            = "sow"
+	OptionTimeAllowed                  = "timeAllowed"
 	OptionFilter                       = "fq"
 	OptionFieldList                    = "fl"
 	OptionRows                         = "rows"
@@ -26,6 +30,7 @@ const (
 	OptionWaitSearcher                 = "waitSearcher"
 	OptionMaxSegments                  = "maxSegments"
 	OptionExpungeDeletes               = "expungeDeletes"
+	OptionVersions                     = "versions"
 	OptionMM                           = "mm"
 	OptionBoost                        = "boost"
 	OptionQueryFields                  = "qf"
@@ -52,7 +57,23 @@ const (
 	OptionMissing                      = "missing"
 	OptionMinCount                     = "mincount"
 	OptionExcludeTerms                 = "excludeTerms"
+	OptionFacetSort                    = "sort"
+	OptionFacetOffset                  = "offset"
+	OptionFacetMethod                  = "method"
 	OptionFacetPivot                   = "facet.pivot"
+	OptionFacetQuery                   = "facet.query"
+	OptionFacetRange                   = "facet.range"
+	OptionRangeStart                   = "range.start"
+	OptionRangeEnd                     = "range.end"
+	OptionRangeGap                     = "range.gap"
+	OptionRangeHardEnd                 = "range.hardend"
+	OptionRangeInclude                 = "range.include"
+	OptionRangeOther                   = "range.other"
+	OptionStats                        = "stats"
+	OptionReRankQuery                  = "rq"
+	OptionReRankQueryValue             = "rqq"
+	OptionStatsField                   = "stats.field"
+	OptionStatsFacet                   = "stats.facet"
 	OptionGroup                        = "group"
 	OptionGroupField                   = "group.field"
 	OptionGroupNGroups                 = "group.ngroups"
@@ -64,6 +85,8 @@ const (
 	ReturnTypeJSON                     = "json"
 	QOperationOR                       = "OR"
 	QOperationAND                      = "AND"
+	OperatorAND              Operator  = "AND"
+	OperatorOR               Operator  = "OR"
 	DefTypeDisMax            DefType   = "dismax"
 	DefTypeEDisMax           DefType   = "edismax"
 	DefTypeStandard          DefType   = "lucene"
@@ -97,10 +120,23 @@ func (dt DefType) isValid() bool {
 	return !(dt != DefTypeDisMax && dt != DefTypeEDisMax && dt != DefTypeStandard)
 }
 
+// Operator is used to restrict the available default operators (`q.op`)
+// of a `/search` request
+type Operator string
+
+func (o Operator) String() string {
+	return string(o)
+}
+
+func (o Operator) isValid() bool {
+	return o == OperatorAND || o == OperatorOR
+}
+
 // Returned validation errors
 var (
 	ErrInvalidDefType   = errors.New("invalid defType, please use one of the provided ones")
 	ErrInvalidDebugType = errors.New("invalid debugType, please use one of the provided ones")
+	ErrInvalidOperator  = errors.New("invalid operator, please use one of the provided ones")
 )
 
 // WriteOptions contains options for write actions. Those include:
@@ -108,10 +144,58 @@ var (
 // CommitWithin: Autocommit all changes after the specified
 // time (in miliseconds)
 // AllowDuplicate: Allows uniqueKey duplication
+// SkipValidation: Skips the client-side JSON validation of the
+// provided documents (Create & BatchCreate only)
+// Handler: Overrides the default update handler path (e.g. to
+// target a handler with a custom update chain)
+// RouteField: The field used for routing documents to shards
+// (router.field), which atomic updates must then include
+// (Update only)
+// Debug: Requests debug information from solr, which is then
+// returned in the Debug attribute of the response
+// DoNotWaitSearcher: Returns as soon as the commit requested along
+// with the changes is done, without waiting for a new searcher
+// to be opened (useful for bulk loading)
+// ChunkSize: The maximum number of ids deleted per request
+// (DeleteByIDs only, default: 1000)
+// ReturnVersions: Requests the ids & versions of the added and
+// deleted documents, which are returned in the Adds & Deletes
+// attributes of the response (check `Response.Affected`)
+// Commit, CommitWithin & AllowDuplicate are sent as request params,
+// which are honored by both the `/update` & `/update/json/docs`
+// handlers.
 type WriteOptions struct {
-	Commit         bool
-	CommitWithin   int64
-	AllowDuplicate bool
+	Commit            bool
+	CommitWithin      int64
+	AllowDuplicate    bool
+	SkipValidation    bool
+	Handler           string
+	RouteField        string
+	Debug             bool
+	DoNotWaitSearcher bool
+	ChunkSize         int
+	ReturnVersions    bool
+}
+
+// DefaultChunkSize is the default maximum number of ids deleted per request
+const DefaultChunkSize = 1000
+
+func (opts *WriteOptions) chunkSize() int {
+	if opts == nil || opts.ChunkSize <= 0 {
+		return DefaultChunkSize
+	}
+	return opts.ChunkSize
+}
+
+func (opts *WriteOptions) handlerPath(defaultPath string) string {
+	if opts == nil || opts.Handler == "" {
+		return defaultPath
+	}
+	return formatHandlerPath(opts.Handler)
+}
+
+func (opts *WriteOptions) skipValidation() bool {
+	return opts != nil && opts.SkipValidation
 }
 
 func (opts *WriteOptions) formatQueryFromOpts() url.Values {
@@ -129,6 +213,26 @@ func (opts *WriteOptions) formatQueryFromOpts() url.Values {
 	if opts.AllowDuplicate {
 		q.Set(OptionOverwrite, "false")
 	}
+	if opts.Debug {
+		q.Set(OptionDebug, "true")
+	}
+	if opts.DoNotWaitSearcher {
+		q.Set(OptionWaitSearcher, "false")
+	}
+	if opts.ReturnVersions {
+		q.Set(OptionVersions, "true")
+	}
+	return q
+}
+
+// formatChunkQueryFromOpts formats the options of an intermediate request of
+// a chunked update, leaving out the ones that trigger a commit, which are
+// only sent along with the last chunk.
+func (opts *WriteOptions) formatChunkQueryFromOpts() url.Values {
+	q := opts.formatQueryFromOpts()
+	q.Del(OptionCommit)
+	q.Del(OptionCommitWithin)
+	q.Del(OptionWaitSearcher)
 	return q
 }
 
@@ -145,9 +249,10 @@ type ReadOptions struct {
 // Query represents the query parameters of a search. It provides
 // helper methods for most of the available solr query params.
 type Query struct {
-	q      []string
-	qOp    string
-	params url.Values
+	q           []string
+	qOp         string
+	params      url.Values
+	noForceJSON bool
 }
 
 // NewQuery returns an initialized Query. It accepts as options a result
@@ -171,6 +276,30 @@ func NewQuery(opts *ReadOptions) *Query {
 	return nq
 }
 
+// NewQueryWithValidation is the same as NewQuery, with the difference that it
+// returns an error when the provided Debug or DefType are set but invalid,
+// instead of silently ignoring them.
+func NewQueryWithValidation(opts *ReadOptions) (*Query, error) {
+	err := opts.validate()
+	if err != nil {
+		return nil, err
+	}
+	return NewQuery(opts), nil
+}
+
+func (o *ReadOptions) validate() error {
+	if o == nil {
+		return nil
+	}
+	if o.Debug != "" && !o.Debug.isValid() {
+		return ErrInvalidDebugType
+	}
+	if o.DefType != "" && !o.DefType.isValid() {
+		return ErrInvalidDefType
+	}
+	return nil
+}
+
 // AddParam allows the addition of custom query parameters.
 func (q *Query) AddParam(key, value string) {
 	q.params.Add(key, value)
@@ -210,16 +339,61 @@ func (q *Query) DelQuery() {
 	q.q = []string{}
 }
 
-// SetOperationAND sets the operation for the Q parameter
-// to AND (only when using `AddQuery`)
+// SetOperationAND sets the operation for the Q parameter to AND. It is used
+// to join the clauses added with `AddQuery` and is also sent as the `q.op`
+// param, so it applies to queries set with `SetQuery` as well. Note that
+// `q.op` is still taken into account by the eDisMax parser, in which
+// case it affects the default of the `mm` param.
 func (q *Query) SetOperationAND() {
 	q.qOp = QOperationAND
+	q.params.Set(OptionQOperation, QOperationAND)
 }
 
-// SetOperationOR sets the operation for the Q parameter
-// to OR (only when using `AddQuery`)
+// SetOperationOR sets the operation for the Q parameter to OR. It is used
+// to join the clauses added with `AddQuery` and is also sent as the `q.op`
+// param (check `SetOperationAND`).
 func (q *Query) SetOperationOR() {
 	q.qOp = QOperationOR
+	q.params.Set(OptionQOperation, QOperationOR)
+}
+
+// SetDefaultField sets the default field (`df`) that is searched when no
+// field is specified in the query.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/the-standard-query-parser.html#standard-query-parser-parameters
+func (q *Query) SetDefaultField(field string) {
+	q.params.Set(OptionDefaultField, field)
+}
+
+// SetSplitOnWhitespace sets the `sow` param, which determines whether the query
+// text is split on whitespace before being analyzed. Setting it to false allows
+// multi-word synonyms to match when using the eDisMax parser.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/the-extended-dismax-query-parser.html#sow-parameter
+func (q *Query) SetSplitOnWhitespace(split bool) {
+	q.params.Set(OptionSplitOnWhitespace, strconv.FormatBool(split))
+}
+
+// SetTimeAllowed sets the amount of time, in milliseconds, allowed for the search
+// to complete. If it is exceeded, solr returns the partial results gathered so far
+// and flags them with `ResponseHeader.PartialResults`.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/common-query-parameters.html#timeallowed-parameter
+func (q *Query) SetTimeAllowed(ms int) {
+	q.params.Set(OptionTimeAllowed, strconv.Itoa(ms))
+}
+
+// SetDefaultOperator sets the default operator (`q.op`) used by solr between the
+// clauses of the whole query string. Unlike `SetOperationAND` & `SetOperationOR`
+// it does not affect how the clauses added with `AddQuery` are joined.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/the-standard-query-parser.html#standard-query-parser-parameters
+func (q *Query) SetDefaultOperator(op Operator) error {
+	if !op.isValid() {
+		return ErrInvalidOperator
+	}
+	q.params.Set(OptionQOperation, op.String())
+	return nil
 }
 
 // AddFilter adds a key-value pair on which to filter the query.
@@ -229,6 +403,141 @@ func (q *Query) AddFilter(key, value string) {
 	q.params.Add(OptionFilter, fmt.Sprintf("%s:%s", key, value))
 }
 
+// ErrInvalidDateRange is returned when neither bound of a date range is provided
+var ErrInvalidDateRange = errors.New("invalid date range, at least one bound is required")
+
+// AddDateRangeFilter adds a filter restricting the given date field to the range between
+// from and to (inclusive), which may be dates or date math expressions, e.g. `NOW-7DAYS`
+// and `NOW` for the last 7 days. An empty bound leaves that end of the range open,
+// but at least one of them must be provided.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/working-with-dates.html#date-math
+func (q *Query) AddDateRangeFilter(field, from, to string) error {
+	if from == "" && to == "" {
+		return ErrInvalidDateRange
+	}
+	if from == "" {
+		from = "*"
+	}
+	if to == "" {
+		to = "*"
+	}
+	q.AddFilter(field, fmt.Sprintf("[%s TO %s]", from, to))
+	return nil
+}
+
+// AddTaggedFilter adds a key-value pair on which to filter the query, marked with the
+// given tag. Facets can then exclude the tagged filter when counting (check
+// `Facet.ExcludeTags`), which is needed for multi-select faceting.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/faceting.html#tagging-and-excluding-filters
+func (q *Query) AddTaggedFilter(tag, key, value string) {
+	q.params.Add(OptionFilter, fmt.Sprintf("{!tag=%s}%s:%s", formatLocalParamValue(tag), key, value))
+}
+
+// FilterOptions are the local params that can be provided to a filter. Those include:
+// Tag: Marks the filter with the given tag (check `AddTaggedFilter`)
+// NoCache: Disables caching the results of the filter in the filter cache,
+// which is useful for filters that are unlikely to be repeated
+// Cost: Sets the order in which non cached filters are evaluated (lower
+// first). Non cached filters with a cost of 100 or more are run as
+// post filters, if supported by their query parser
+type FilterOptions struct {
+	Tag     string
+	NoCache bool
+	Cost    int
+}
+
+func (o *FilterOptions) localParams() string {
+	if o == nil {
+		return ""
+	}
+	var params []string
+	if o.Tag != "" {
+		params = append(params, "tag="+formatLocalParamValue(o.Tag))
+	}
+	if o.NoCache {
+		params = append(params, "cache=false")
+	}
+	if o.Cost > 0 {
+		params = append(params, "cost="+strconv.Itoa(o.Cost))
+	}
+	if len(params) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("{!%s}", strings.Join(params, " "))
+}
+
+// AddFilterWithOptions adds a key-value pair on which to filter the query, preceded by the local
+// params of the provided options, e.g. `{!cache=false cost=100}key:value` for an expensive filter.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/common-query-parameters.html#cache-parameter
+func (q *Query) AddFilterWithOptions(key, value string, opts *FilterOptions) {
+	q.params.Add(OptionFilter, fmt.Sprintf("%s%s:%s", opts.localParams(), key, value))
+}
+
+// FilterByIDs restricts the results to the documents with the given ids, using the
+// terms query parser which is far more efficient than a long boolean query
+// when filtering on a large set of ids. Check `FormatTermsQuery` for the
+// returned error.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/other-parsers.html#terms-query-parser
+func (q *Query) FilterByIDs(idField string, ids []string) error {
+	return q.AddTermsFilter(idField, ids)
+}
+
+// ExplainDocs requests the score explanation of the documents with the given ids only,
+// keeping the debug information small. The results are restricted to those documents
+// with a filter, which does not affect their score, and the explanations are
+// found through `Response.Explain`.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/common-query-parameters.html#debug-parameter
+func (q *Query) ExplainDocs(idField string, ids []string) error {
+	err := q.AddTermsFilter(idField, ids)
+	if err != nil {
+		return err
+	}
+	q.params.Set(OptionDebug, DebugTypeResults.String())
+	return nil
+}
+
+// TermsQuery sets the Q parameter of the query to a terms query, matching the
+// documents whose field contains any of the given values. Check
+// `FormatTermsQuery` for how the values are separated.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/other-parsers.html#terms-query-parser
+func (q *Query) TermsQuery(field string, values []string) error {
+	tq, err := FormatTermsQuery(field, values)
+	if err != nil {
+		return err
+	}
+	q.SetQuery(tq)
+	return nil
+}
+
+// AddTermsFilter adds a terms query filter, restricting the results to the
+// documents whose field contains any of the given values. Check
+// `FormatTermsQuery` for how the values are separated.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/other-parsers.html#terms-query-parser
+func (q *Query) AddTermsFilter(field string, values []string) error {
+	tq, err := FormatTermsQuery(field, values)
+	if err != nil {
+		return err
+	}
+	q.params.Add(OptionFilter, tq)
+	return nil
+}
+
+// SetMinScore filters out the documents whose score for the main query is lower than the
+// given threshold. Since solr has no native minimum score parameter, this adds a
+// function range filter on the score of the main query (`query($q)`).
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/other-parsers.html#function-range-query-parser
+func (q *Query) SetMinScore(threshold float64) {
+	q.params.Add(OptionFilter, fmt.Sprintf("{!frange l=%s}query($%s)", strconv.FormatFloat(threshold, 'f', -1, 64), OptionQ))
+}
+
 // SetFilter gives the option to set a filter allowing for more complex logic instead
 // of a basic key-value check.
 func (q *Query) SetFilter(value string) {
@@ -242,6 +551,44 @@ func (q *Query) AddField(value string) {
 	q.params.Add(OptionFieldList, value)
 }
 
+// AddFieldGlob adds the fields matching the given glob pattern (e.g. `attr_*`) to the
+// returned field list. Each call to this method or to `AddField` is sent as a
+// separate `fl` parameter, so globs and explicit fields can be combined.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/common-query-parameters.html#fl-field-list-parameter
+func (q *Query) AddFieldGlob(pattern string) {
+	q.params.Add(OptionFieldList, pattern)
+}
+
+// AddFieldAlias adds a field to the returned field list under the given alias, whose value
+// is the result of the expression, e.g. a function query such as `product(price,0.9)`
+// or another field. The expression is URL encoded along with the rest of the query.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/common-query-parameters.html#field-name-aliases
+func (q *Query) AddFieldAlias(alias, expression string) {
+	q.params.Add(OptionFieldList, alias+":"+expression)
+}
+
+// IncludeScore adds the score of each document to the returned field list. If no
+// field has been added yet, all fields are requested as well (`*,score`),
+// since requesting only the score would omit every other field.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/common-query-parameters.html#fl-field-list-parameter
+func (q *Query) IncludeScore() {
+	fields := q.params[OptionFieldList]
+	if len(fields) == 0 {
+		q.params.Add(OptionFieldList, "*")
+	}
+	for _, f := range fields {
+		for _, v := range strings.Split(f, ",") {
+			if strings.TrimSpace(v) == "score" {
+				return
+			}
+		}
+	}
+	q.params.Add(OptionFieldList, "score")
+}
+
 // SetStart enables setting the starting index for a search query. It can be used when
 // the available results are more than the rows returned to fetch the remainder rows.
 // More info:
@@ -266,15 +613,47 @@ func (q *Query) SetRows(value int) {
 	q.params.Set(OptionRows, sv)
 }
 
+// clone returns a copy of the query that can be modified without
+// affecting the original one.
+func (q *Query) clone() *Query {
+	nq := *q
+	nq.q = append([]string(nil), q.q...)
+	nq.params = make(url.Values, len(q.params))
+	for k, v := range q.params {
+		nq.params[k] = append([]string(nil), v...)
+	}
+	return &nq
+}
+
 // String returns the string representation of the query.
 func (q *Query) String() string {
 	if len(q.q) > 0 {
 		q.params.Set(OptionQ, strings.Join(q.q, fmt.Sprintf(" %s ", q.qOp)))
 	}
-	q.params.Set(OptionWT, ReturnTypeJSON)
+	if !q.noForceJSON {
+		q.params.Set(OptionWT, ReturnTypeJSON)
+	}
 	return q.params.Encode()
 }
 
+// SetForceJSON sets whether `String` forces the `wt` param to json, which is the
+// default. When disabled the `wt` param is left untouched, allowing it to be
+// controlled with `SetResponseWriter` (e.g. for logging or for building queries
+// for non-JSON handlers). Note that the clients of this library can only
+// parse JSON responses.
+func (q *Query) SetForceJSON(force bool) {
+	q.noForceJSON = !force
+}
+
+// SetResponseWriter sets the `wt` param, which determines the format of the
+// response. It only takes effect when forcing JSON is disabled with
+// `SetForceJSON(false)`.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/response-writers.html
+func (q *Query) SetResponseWriter(wt string) {
+	q.params.Set(OptionWT, wt)
+}
+
 // CollapseParams are the available params that can be set when using
 // the Collapsing Query Parser
 type CollapseParams struct {
@@ -318,6 +697,18 @@ var (
 	ErrInvalidHint       = errors.New("invalid hint, please use one of the provided")
 )
 
+// formatLocalParamValue quotes the given local param value when it contains
+// characters that would otherwise end the value prematurely, or when it
+// starts with a double quote that would be taken as the start of a
+// quoted value.
+func formatLocalParamValue(v string) string {
+	if !strings.ContainsAny(v, " '}") && !strings.HasPrefix(v, `"`) {
+		return v
+	}
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	return "'" + strings.ReplaceAll(v, "'", `\'`) + "'"
+}
+
 func paramFormat(k, v string) string {
 	return fmt.Sprintf("%s=%s", k, v)
 }
@@ -466,9 +857,38 @@ type Facet struct {
 	Prefix       string
 	Contains     string
 	Limit        int
+	Offset       int
 	MinCount     int
 	Missing      bool
+	Sort         string
+	Method       string
 	ExcludeTerms []string
+	ExcludeTags  []string
+}
+
+// Available values of the Facet Sort & Method options
+const (
+	FacetSortCount  = "count"
+	FacetSortIndex  = "index"
+	FacetMethodEnum = "enum"
+	FacetMethodFC   = "fc"
+	FacetMethodFCS  = "fcs"
+)
+
+// Possible errors returned from improper use of the Facet options
+var (
+	ErrInvalidFacetSort   = errors.New("invalid facet sort, please use one of the provided")
+	ErrInvalidFacetMethod = errors.New("invalid facet method, please use one of the provided")
+)
+
+func (f *Facet) validate() error {
+	if f.Sort != "" && f.Sort != FacetSortCount && f.Sort != FacetSortIndex {
+		return ErrInvalidFacetSort
+	}
+	if f.Method != "" && f.Method != FacetMethodEnum && f.Method != FacetMethodFC && f.Method != FacetMethodFCS {
+		return ErrInvalidFacetMethod
+	}
+	return nil
 }
 
 func (f *Facet) format(param string) string {
@@ -477,12 +897,22 @@ func (f *Facet) format(param string) string {
 
 // AddFacet adds a facet to the query, along with field specific options.
 // Not all options are supported, but functions like AddParam, SetParam
-// can help with those missing options.
+// can help with those missing options. Filters marked with any of the
+// ExcludeTags are ignored when counting the facet. An error is returned
+// if the Sort or Method options are not one of the provided values.
 // More info:
 // https://lucene.apache.org/solr/guide/8_5/faceting.html
-func (q *Query) AddFacet(f *Facet) {
+func (q *Query) AddFacet(f *Facet) error {
+	err := f.validate()
+	if err != nil {
+		return err
+	}
 	q.params.Set(OptionFacet, "true")
-	q.params.Add(OptionFacetField, f.Field)
+	if len(f.ExcludeTags) > 0 {
+		q.params.Add(OptionFacetField, fmt.Sprintf("{!ex=%s}%s", formatLocalParamValue(strings.Join(f.ExcludeTags, ",")), f.Field))
+	} else {
+		q.params.Add(OptionFacetField, f.Field)
+	}
 	if f.MinCount > 0 {
 		minCount := strconv.Itoa(f.MinCount)
 		q.params.Set(f.format(OptionMinCount), minCount)
@@ -500,9 +930,127 @@ func (q *Query) AddFacet(f *Facet) {
 	if f.Missing {
 		q.params.Set(f.format(OptionMissing), "true")
 	}
-	if len(f.ExcludeTerms) > 1 {
-		q.params.Set(f.format(OptionExcludeTerms), "true")
+	if f.Offset > 0 {
+		q.params.Set(f.format(OptionFacetOffset), strconv.Itoa(f.Offset))
+	}
+	if f.Sort != "" {
+		q.params.Set(f.format(OptionFacetSort), f.Sort)
+	}
+	if f.Method != "" {
+		q.params.Set(f.format(OptionFacetMethod), f.Method)
+	}
+	if len(f.ExcludeTerms) > 0 {
+		q.params.Set(f.format(OptionExcludeTerms), strings.Join(f.ExcludeTerms, ","))
+	}
+	return nil
+}
+
+// RangeFacet represents a range facet for a specific field along with
+// the options for that facet. Start, End & Gap are required by solr.
+type RangeFacet struct {
+	Field   string
+	Start   string
+	End     string
+	Gap     string
+	Other   []RangeOther
+	Include []RangeInclude
+	HardEnd bool
+}
+
+// RangeInclude determines which bounds of each range are included in its count
+type RangeInclude string
+
+func (i RangeInclude) String() string {
+	return string(i)
+}
+
+func (i RangeInclude) isValid() bool {
+	switch i {
+	case RangeIncludeLower, RangeIncludeUpper, RangeIncludeEdge, RangeIncludeOuter, RangeIncludeAll:
+		return true
+	}
+	return false
+}
+
+// RangeOther determines which counts are computed in addition to the ranges
+type RangeOther string
+
+func (o RangeOther) String() string {
+	return string(o)
+}
+
+func (o RangeOther) isValid() bool {
+	switch o {
+	case RangeOtherBefore, RangeOtherAfter, RangeOtherBetween, RangeOtherNone, RangeOtherAll:
+		return true
+	}
+	return false
+}
+
+// Constants to secure proper RangeInclude & RangeOther usage
+const (
+	RangeIncludeLower RangeInclude = "lower"
+	RangeIncludeUpper RangeInclude = "upper"
+	RangeIncludeEdge  RangeInclude = "edge"
+	RangeIncludeOuter RangeInclude = "outer"
+	RangeIncludeAll   RangeInclude = "all"
+	RangeOtherBefore  RangeOther   = "before"
+	RangeOtherAfter   RangeOther   = "after"
+	RangeOtherBetween RangeOther   = "between"
+	RangeOtherNone    RangeOther   = "none"
+	RangeOtherAll     RangeOther   = "all"
+)
+
+// Possible errors returned from improper use of the RangeFacet options
+var (
+	ErrInvalidRangeInclude = errors.New("invalid range include, please use one of the provided")
+	ErrInvalidRangeOther   = errors.New("invalid range other, please use one of the provided")
+)
+
+func (f *RangeFacet) validate() error {
+	for _, i := range f.Include {
+		if !i.isValid() {
+			return ErrInvalidRangeInclude
+		}
+	}
+	for _, o := range f.Other {
+		if !o.isValid() {
+			return ErrInvalidRangeOther
+		}
+	}
+	return nil
+}
+
+func (f *RangeFacet) format(param string) string {
+	return fmt.Sprintf("f.%s.facet.%s", f.Field, param)
+}
+
+// AddRangeFacet adds a range facet to the query, along with field specific options.
+// It can be combined with any other facet added to the query. The results are
+// found in `FacetCounts.Ranges`. An error is returned if any of the Include
+// or Other options is not one of the provided values.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/faceting.html#range-faceting
+func (q *Query) AddRangeFacet(f *RangeFacet) error {
+	err := f.validate()
+	if err != nil {
+		return err
+	}
+	q.params.Set(OptionFacet, "true")
+	q.params.Add(OptionFacetRange, f.Field)
+	q.params.Set(f.format(OptionRangeStart), f.Start)
+	q.params.Set(f.format(OptionRangeEnd), f.End)
+	q.params.Set(f.format(OptionRangeGap), f.Gap)
+	if f.HardEnd {
+		q.params.Set(f.format(OptionRangeHardEnd), "true")
+	}
+	for _, i := range f.Include {
+		q.params.Add(f.format(OptionRangeInclude), i.String())
 	}
+	for _, o := range f.Other {
+		q.params.Add(f.format(OptionRangeOther), o.String())
+	}
+	return nil
 }
 
 // AddFacetPivot adds a facet pivot. The given fieldsString should contain the fields
@@ -520,6 +1068,210 @@ func (q *Query) AddFacetPivot(fieldsString string, minCount int) {
 	}
 }
 
+// AddFacetQuery adds a facet query, returning the number of documents matching the given
+// query (check `FacetCounts.Queries` where the query is the key). It can be called
+// multiple times. When exclude tags are given, the filters marked with those tags
+// are ignored while counting (multi-select faceting).
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/faceting.html#facet-query-parameter
+func (q *Query) AddFacetQuery(query string, excludeTags ...string) {
+	q.params.Set(OptionFacet, "true")
+	if len(excludeTags) == 0 {
+		q.params.Add(OptionFacetQuery, query)
+		return
+	}
+	q.params.Add(OptionFacetQuery, fmt.Sprintf("{!ex=%s}%s", formatLocalParamValue(strings.Join(excludeTags, ",")), query))
+}
+
+// AddFacetQueryTagged adds a facet query whose count is returned under the given label
+// instead of the raw query string (check `FacetCounts.Queries`), by using the
+// `{!key=label}` local param.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/faceting.html#changing-the-output-key
+func (q *Query) AddFacetQueryTagged(label, query string) {
+	q.params.Set(OptionFacet, "true")
+	q.params.Add(OptionFacetQuery, fmt.Sprintf("{!key=%s}%s", formatLocalParamValue(label), query))
+}
+
+// FacetPivotParams contains the available parameters for a facet pivot. Of all the
+// params only Fields is required and it contains the fields to be faceted in the
+// given order. StatsTags contains the tags of the stats fields (check
+// `AddTaggedStatsField`) to be computed for each level of the pivot.
+type FacetPivotParams struct {
+	Fields    []string
+	MinCount  int
+	StatsTags []string
+}
+
+// FacetPivot adds a facet pivot using the given params. When stats tags are
+// provided the pivot is formatted as `{!stats=tag1,tag2}field1,field2`
+// so that the stats of the tagged stats fields are returned for each
+// pivot (see `Pivot.Stats`).
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/faceting.html#combining-stats-component-with-pivots
+func (q *Query) FacetPivot(params *FacetPivotParams) error {
+	if params == nil || len(params.Fields) == 0 {
+		return ErrParamsRequired
+	}
+	pivot := strings.Join(params.Fields, ",")
+	if len(params.StatsTags) > 0 {
+		pivot = fmt.Sprintf("{!stats=%s}%s", formatLocalParamValue(strings.Join(params.StatsTags, ",")), pivot)
+	}
+	q.AddFacetPivot(pivot, params.MinCount)
+	return nil
+}
+
+// AddStatsField enables the stats component and adds a field for which statistics
+// (min, max, mean etc.) are computed over the results (check `Response.Stats`).
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/the-stats-component.html
+func (q *Query) AddStatsField(field string) {
+	q.AddTaggedStatsField("", field)
+}
+
+// AddStatsFacet enables the stats component and adds a field on which the computed
+// statistics are faceted, returning sub-results for each value of the field.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/the-stats-component.html#stats-component-parameters
+func (q *Query) AddStatsFacet(field string) {
+	q.params.Set(OptionStats, "true")
+	q.params.Add(OptionStatsFacet, field)
+}
+
+// AddTaggedStatsField enables the stats component and adds a stats field marked
+// with the given tag, which can then be referenced by a facet pivot.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/the-stats-component.html
+func (q *Query) AddTaggedStatsField(tag, field string) {
+	q.params.Set(OptionStats, "true")
+	if tag == "" {
+		q.params.Add(OptionStatsField, field)
+		return
+	}
+	q.params.Add(OptionStatsField, fmt.Sprintf("{!tag=%s}%s", formatLocalParamValue(tag), field))
+}
+
+// ErrInvalidReRankDocs is returned when the number of documents to re-rank is not positive
+var ErrInvalidReRankDocs = errors.New("the number of documents to re-rank must be greater than zero")
+
+// ReRank sets a re-rank query which re-scores the top N (docs) documents of the results
+// using the given query. The score of the re-rank query is multiplied by the given
+// weight and added to the original score. The query is passed to solr through
+// the `rqq` parameter.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/query-re-ranking.html#rerank-query-parser
+func (q *Query) ReRank(query string, docs int, weight float64) error {
+	if docs <= 0 {
+		return ErrInvalidReRankDocs
+	}
+	rq := fmt.Sprintf("{!rerank reRankQuery=$%s reRankDocs=%d reRankWeight=%s}",
+		OptionReRankQueryValue, docs, strconv.FormatFloat(weight, 'f', -1, 64))
+	q.params.Set(OptionReRankQuery, rq)
+	q.params.Set(OptionReRankQueryValue, query)
+	return nil
+}
+
+func formatEFI(efi map[string]string) string {
+	keys := make([]string, 0, len(efi))
+	for k := range efi {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var params []string
+	for _, k := range keys {
+		params = append(params, paramFormat("efi."+k, formatLocalParamValue(efi[k])))
+	}
+	return strings.Join(params, " ")
+}
+
+// LTRRerank re-ranks the top N (docs) documents of the results using the given
+// Learning To Rank model. Any external feature information (efi) required
+// by the model's features can be passed along as a map. The LTR plugin
+// must be enabled on the solr server.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/learning-to-rank.html#running-a-rerank-query
+func (q *Query) LTRRerank(model string, docs int, efi map[string]string) error {
+	if docs <= 0 {
+		return ErrInvalidReRankDocs
+	}
+	params := []string{paramFormat("model", formatLocalParamValue(model)), paramFormat("reRankDocs", strconv.Itoa(docs))}
+	if len(efi) > 0 {
+		params = append(params, formatEFI(efi))
+	}
+	q.params.Set(OptionReRankQuery, fmt.Sprintf("{!ltr %s}", strings.Join(params, " ")))
+	return nil
+}
+
+// IncludeFeatures adds the `[features]` transformer to the returned field list, so
+// that the feature values extracted by the LTR plugin are returned for each
+// document (check `Doc.Features`). Any external feature information can be
+// passed along as a map.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/learning-to-rank.html#extracting-features
+func (q *Query) IncludeFeatures(efi map[string]string) {
+	if len(efi) == 0 {
+		q.params.Add(OptionFieldList, "[features]")
+		return
+	}
+	q.params.Add(OptionFieldList, fmt.Sprintf("[features %s]", formatEFI(efi)))
+}
+
+// Possible errors returned from improper use of the spatial helpers
+var (
+	ErrInvalidCoordinates = errors.New("invalid coordinates, latitude must be within [-90, 90] and longitude within [-180, 180]")
+	ErrInvalidDistance    = errors.New("invalid distance, it must be greater than zero")
+)
+
+func formatPoint(lat, lon float64) (string, error) {
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return "", ErrInvalidCoordinates
+	}
+	return fmt.Sprintf("%s,%s", strconv.FormatFloat(lat, 'f', -1, 64), strconv.FormatFloat(lon, 'f', -1, 64)), nil
+}
+
+func (q *Query) addSpatialFilter(parser, field string, lat, lon, distanceKm float64) error {
+	if distanceKm <= 0 {
+		return ErrInvalidDistance
+	}
+	pt, err := formatPoint(lat, lon)
+	if err != nil {
+		return err
+	}
+	d := strconv.FormatFloat(distanceKm, 'f', -1, 64)
+	q.params.Add(OptionFilter, fmt.Sprintf("{!%s sfield=%s pt=%s d=%s}", parser, field, pt, d))
+	return nil
+}
+
+// GeoFilter restricts the results to the documents whose location field is within the
+// given distance (in kilometers) from the given point.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/spatial-search.html#geofilt
+func (q *Query) GeoFilter(field string, lat, lon, distanceKm float64) error {
+	return q.addSpatialFilter("geofilt", field, lat, lon, distanceKm)
+}
+
+// BBoxFilter restricts the results to the documents whose location field is within the
+// bounding box of the circle with the given distance (in kilometers) as radius and
+// the given point as center. It is faster but less accurate than GeoFilter.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/spatial-search.html#bbox
+func (q *Query) BBoxFilter(field string, lat, lon, distanceKm float64) error {
+	return q.addSpatialFilter("bbox", field, lat, lon, distanceKm)
+}
+
+// GeoDist returns the function query calculating the distance between the given location
+// field and point, which can be used for sorting, boosting or as a returned field.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/spatial-search.html#geodist
+func GeoDist(field string, lat, lon float64) (string, error) {
+	pt, err := formatPoint(lat, lon)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("geodist(%s,%s)", field, pt), nil
+}
+
 // GroupParams contains the available parameters to finetune result
 // grouping. Of all the params only Field is required
 type GroupParams struct {
@@ -1,6 +1,8 @@
 package solr
 
 import (
+	"net/url"
+	"strings"
 	"testing"
 )
 
@@ -276,11 +278,17 @@ func TestAddFacet(t *testing.T) {
 		Prefix:       "v",
 		Contains:     "v",
 		Limit:        10,
+		Offset:       20,
 		MinCount:     5,
 		Missing:      false,
+		Sort:         FacetSortIndex,
+		Method:       FacetMethodEnum,
 		ExcludeTerms: []string{"term1", "term2"},
 	}
-	q.AddFacet(f)
+	err := q.AddFacet(f)
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
 	if q.params.Get("facet") == "" {
 		t.Fatal("facet param not registered")
 	}
@@ -299,9 +307,30 @@ func TestAddFacet(t *testing.T) {
 	if q.params.Get("f.field.facet.contains") == "" {
 		t.Fatal("f.field.facet.contains param not registered")
 	}
-	if q.params.Get("f.field.facet.excludeTerms") == "" {
+	if q.params.Get("f.field.facet.excludeTerms") != "term1,term2" {
 		t.Fatal("f.field.facet.excludeTerms param not registered")
 	}
+	if q.params.Get("f.field.facet.offset") != "20" {
+		t.Fatal("f.field.facet.offset param not registered")
+	}
+	if q.params.Get("f.field.facet.sort") != "index" {
+		t.Fatal("f.field.facet.sort param not registered")
+	}
+	if q.params.Get("f.field.facet.method") != "enum" {
+		t.Fatal("f.field.facet.method param not registered")
+	}
+
+	err = q.AddFacet(&Facet{Field: "other", Method: "fast"})
+	if err != ErrInvalidFacetMethod {
+		t.Fatalf("expected %v but got %v", ErrInvalidFacetMethod, err)
+	}
+	err = q.AddFacet(&Facet{Field: "other", Sort: "desc"})
+	if err != ErrInvalidFacetSort {
+		t.Fatalf("expected %v but got %v", ErrInvalidFacetSort, err)
+	}
+	if q.params.Get("f.other.facet.sort") != "" {
+		t.Fatal("invalid facet should not register any param")
+	}
 }
 
 func TestAddFacetPivot(t *testing.T) {
@@ -403,3 +432,584 @@ func TestGroupValidFunc(t *testing.T) {
 		t.Fatal("group.func param not registered")
 	}
 }
+
+func TestWriteOptionsSkipValidation(t *testing.T) {
+	var opts *WriteOptions
+	if opts.skipValidation() {
+		t.Fatal("validation should not be skipped by default")
+	}
+	opts = &WriteOptions{SkipValidation: true}
+	if !opts.skipValidation() {
+		t.Fatal("validation should be skipped when requested")
+	}
+	if opts.formatQueryFromOpts().Encode() != "" {
+		t.Fatal("skip validation should not be sent to solr")
+	}
+}
+
+func TestFacetPivotNoParams(t *testing.T) {
+	q := NewQuery(nil)
+	err := q.FacetPivot(&FacetPivotParams{})
+	if err == nil {
+		t.Fatal("expected error but got none")
+	}
+}
+
+func TestFacetPivotStatsTags(t *testing.T) {
+	q := NewQuery(nil)
+	err := q.FacetPivot(&FacetPivotParams{
+		Fields:    []string{"cat", "brand"},
+		StatsTags: []string{"piv"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error but got %s", err)
+	}
+	q.AddTaggedStatsField("piv", "price")
+	if q.params.Get("facet.pivot") != "{!stats=piv}cat,brand" {
+		t.Fatalf("unexpected facet.pivot param: %s", q.params.Get("facet.pivot"))
+	}
+	if q.params.Get("stats") != "true" {
+		t.Fatal("stats param not registered")
+	}
+	if q.params.Get("stats.field") != "{!tag=piv}price" {
+		t.Fatalf("unexpected stats.field param: %s", q.params.Get("stats.field"))
+	}
+	q = NewQuery(nil)
+	err = q.FacetPivot(&FacetPivotParams{
+		Fields:    []string{"cat", "brand"},
+		StatsTags: []string{"price stats"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error but got %s", err)
+	}
+	q.AddTaggedStatsField("price stats", "price")
+	if q.params.Get("facet.pivot") != "{!stats='price stats'}cat,brand" {
+		t.Fatalf("unexpected facet.pivot param: %s", q.params.Get("facet.pivot"))
+	}
+	if q.params.Get("stats.field") != "{!tag='price stats'}price" {
+		t.Fatalf("unexpected stats.field param: %s", q.params.Get("stats.field"))
+	}
+}
+
+func TestIncludeScore(t *testing.T) {
+	q := NewQuery(nil)
+	q.IncludeScore()
+	q.IncludeScore()
+	actual := q.params[OptionFieldList]
+	if len(actual) != 2 || actual[0] != "*" || actual[1] != "score" {
+		t.Fatalf("expected fl to be [* score] but got %v", actual)
+	}
+
+	q = NewQuery(nil)
+	q.AddField("id")
+	q.IncludeScore()
+	actual = q.params[OptionFieldList]
+	if len(actual) != 2 || actual[0] != "id" || actual[1] != "score" {
+		t.Fatalf("expected fl to be [id score] but got %v", actual)
+	}
+}
+
+func TestReRank(t *testing.T) {
+	q := NewQuery(nil)
+	err := q.ReRank("greetings", 0, 3)
+	if err == nil {
+		t.Fatal("expected error but got none")
+	}
+
+	err = q.ReRank("greetings", 1000, 3)
+	if err != nil {
+		t.Fatalf("expected no error but got %s", err)
+	}
+	expected := "{!rerank reRankQuery=$rqq reRankDocs=1000 reRankWeight=3}"
+	if q.params.Get("rq") != expected {
+		t.Fatalf("expected %s but got %s", expected, q.params.Get("rq"))
+	}
+	if q.params.Get("rqq") != "greetings" {
+		t.Fatal("rqq param not registered")
+	}
+}
+
+func TestLTRRerank(t *testing.T) {
+	q := NewQuery(nil)
+	err := q.LTRRerank("myModel", 0, nil)
+	if err == nil {
+		t.Fatal("expected error but got none")
+	}
+
+	err = q.LTRRerank("myModel", 100, map[string]string{"text": "test", "lang": "en"})
+	if err != nil {
+		t.Fatalf("expected no error but got %s", err)
+	}
+	expected := "{!ltr model=myModel reRankDocs=100 efi.lang=en efi.text=test}"
+	if q.params.Get("rq") != expected {
+		t.Fatalf("expected %s but got %s", expected, q.params.Get("rq"))
+	}
+
+	q.IncludeFeatures(map[string]string{"text": "test"})
+	if q.params.Get("fl") != "[features efi.text=test]" {
+		t.Fatalf("unexpected fl param: %s", q.params.Get("fl"))
+	}
+}
+
+func TestLTRRerankQuotesEFI(t *testing.T) {
+	q := NewQuery(nil)
+	err := q.LTRRerank("my model", 10, map[string]string{"text": "night of the living dead"})
+	if err != nil {
+		t.Fatalf("expected no error but got %s", err)
+	}
+	expected := "{!ltr model='my model' reRankDocs=10 efi.text='night of the living dead'}"
+	if q.params.Get("rq") != expected {
+		t.Fatalf("expected %s but got %s", expected, q.params.Get("rq"))
+	}
+
+	q.IncludeFeatures(map[string]string{"text": "a}b"})
+	expected = "[features efi.text='a}b']"
+	if q.params.Get("fl") != expected {
+		t.Fatalf("expected %s but got %s", expected, q.params.Get("fl"))
+	}
+}
+
+func TestAddFieldGlob(t *testing.T) {
+	q := NewQuery(nil)
+	q.AddField("id")
+	q.AddFieldGlob("attr_*")
+	actual := q.String()
+	expected := "fl=id&fl=attr_%2A&wt=json"
+	if actual != expected {
+		t.Fatalf("expected %s but got %s", expected, actual)
+	}
+}
+
+func TestWriteOptionsHandlerPath(t *testing.T) {
+	var opts *WriteOptions
+	if opts.handlerPath("/update") != "/update" {
+		t.Fatal("expected default handler path")
+	}
+	opts = &WriteOptions{Handler: "update/dedupe"}
+	if opts.handlerPath("/update") != "/update/dedupe" {
+		t.Fatalf("expected custom handler path but got %s", opts.handlerPath("/update"))
+	}
+}
+
+func TestFilterByIDs(t *testing.T) {
+	q := NewQuery(nil)
+	err := q.FilterByIDs("id", []string{"1", "2", "3"})
+	if err != nil {
+		t.Fatalf("expected no error but got %s", err)
+	}
+	expected := "{!terms f=id}1,2,3"
+	if q.params.Get("fq") != expected {
+		t.Fatalf("expected %s but got %s", expected, q.params.Get("fq"))
+	}
+}
+
+func TestTermsQuery(t *testing.T) {
+	q := NewQuery(nil)
+	err := q.TermsQuery("tags", []string{"one", "two"})
+	if err != nil {
+		t.Fatalf("expected no error but got %s", err)
+	}
+	expected := "{!terms f=tags}one,two"
+	if q.params.Get("q") != expected {
+		t.Fatalf("expected %s but got %s", expected, q.params.Get("q"))
+	}
+
+	err = q.AddTermsFilter("tags", []string{",|;~^\t"})
+	if err != ErrNoTermsSeparator {
+		t.Fatalf("expected %v but got %v", ErrNoTermsSeparator, err)
+	}
+	if q.params.Get("fq") != "" {
+		t.Fatalf("expected no filter but got %s", q.params.Get("fq"))
+	}
+}
+
+func TestSetMinScore(t *testing.T) {
+	q := NewQuery(nil)
+	q.SetMinScore(0.5)
+	expected := "{!frange l=0.5}query($q)"
+	if q.params.Get("fq") != expected {
+		t.Fatalf("expected %s but got %s", expected, q.params.Get("fq"))
+	}
+}
+
+func TestAddFacetQueryTagged(t *testing.T) {
+	q := NewQuery(nil)
+	q.AddFacetQueryTagged("cheap", "price:[0 TO 10]")
+	if q.params.Get("facet") != "true" {
+		t.Fatal("facet param not registered")
+	}
+	expected := "{!key=cheap}price:[0 TO 10]"
+	if q.params.Get("facet.query") != expected {
+		t.Fatalf("expected %s but got %s", expected, q.params.Get("facet.query"))
+	}
+}
+
+func TestFormatLocalParamValue(t *testing.T) {
+	if formatLocalParamValue("cheap") != "cheap" {
+		t.Fatal("simple values should not be quoted")
+	}
+	expected := `'under 10\'s'`
+	if formatLocalParamValue("under 10's") != expected {
+		t.Fatalf("expected %s but got %s", expected, formatLocalParamValue("under 10's"))
+	}
+	expected = `'"a\\b" c'`
+	if formatLocalParamValue(`"a\b" c`) != expected {
+		t.Fatalf("expected %s but got %s", expected, formatLocalParamValue(`"a\b" c`))
+	}
+	expected = `'"quoted"'`
+	if formatLocalParamValue(`"quoted"`) != expected {
+		t.Fatalf("expected %s but got %s", expected, formatLocalParamValue(`"quoted"`))
+	}
+}
+
+func TestAddStatsField(t *testing.T) {
+	q := NewQuery(nil)
+	q.AddStatsField("price")
+	q.AddStatsFacet("genre")
+	if q.params.Get("stats") != "true" {
+		t.Fatal("stats param not registered")
+	}
+	if q.params.Get("stats.field") != "price" {
+		t.Fatal("stats.field param not registered")
+	}
+	if q.params.Get("stats.facet") != "genre" {
+		t.Fatal("stats.facet param not registered")
+	}
+}
+
+func TestAddRangeFacet(t *testing.T) {
+	q := NewQuery(nil)
+	q.AddFacet(&Facet{Field: "genre"})
+	f := &RangeFacet{
+		Field:   "year",
+		Start:   "1970",
+		End:     "2020",
+		Gap:     "10",
+		Other:   []RangeOther{RangeOtherBefore, RangeOtherAfter},
+		Include: []RangeInclude{RangeIncludeLower},
+		HardEnd: true,
+	}
+	err := q.AddRangeFacet(f)
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if q.params.Get("facet") != "true" {
+		t.Fatal("facet param not registered")
+	}
+	if q.params.Get("facet.field") != "genre" {
+		t.Fatal("facet.field param clobbered")
+	}
+	if q.params.Get("facet.range") != "year" {
+		t.Fatal("facet.range param not registered")
+	}
+	if q.params.Get("f.year.facet.range.start") != "1970" {
+		t.Fatal("f.year.facet.range.start param not registered")
+	}
+	if q.params.Get("f.year.facet.range.end") != "2020" {
+		t.Fatal("f.year.facet.range.end param not registered")
+	}
+	if q.params.Get("f.year.facet.range.gap") != "10" {
+		t.Fatal("f.year.facet.range.gap param not registered")
+	}
+	if q.params.Get("f.year.facet.range.hardend") != "true" {
+		t.Fatal("f.year.facet.range.hardend param not registered")
+	}
+	if q.params.Get("f.year.facet.range.include") != "lower" {
+		t.Fatal("f.year.facet.range.include param not registered")
+	}
+	if len(q.params["f.year.facet.range.other"]) != 2 {
+		t.Fatal("f.year.facet.range.other param not registered")
+	}
+
+	err = q.AddRangeFacet(&RangeFacet{Field: "price", Include: []RangeInclude{"lowest"}})
+	if err != ErrInvalidRangeInclude {
+		t.Fatalf("expected %v but got %v", ErrInvalidRangeInclude, err)
+	}
+	err = q.AddRangeFacet(&RangeFacet{Field: "price", Other: []RangeOther{"beyond"}})
+	if err != ErrInvalidRangeOther {
+		t.Fatalf("expected %v but got %v", ErrInvalidRangeOther, err)
+	}
+	if q.params.Get("f.price.facet.range.start") != "" || len(q.params["facet.range"]) != 1 {
+		t.Fatal("invalid range facet should not register any param")
+	}
+}
+
+func TestAddFacetQuery(t *testing.T) {
+	q := NewQuery(nil)
+	q.AddFacetQuery("price:[0 TO 10]")
+	q.AddFacetQuery("price:[10 TO *]", "price")
+	if q.params.Get("facet") != "true" {
+		t.Fatal("facet param not registered")
+	}
+	actual := q.params["facet.query"]
+	if len(actual) != 2 || actual[0] != "price:[0 TO 10]" || actual[1] != "{!ex=price}price:[10 TO *]" {
+		t.Fatalf("unexpected facet.query params: %v", actual)
+	}
+
+	q = NewQuery(nil)
+	q.AddFacetQuery("price:[10 TO *]", "price range", "stock")
+	if q.params.Get("facet.query") != "{!ex='price range,stock'}price:[10 TO *]" {
+		t.Fatalf("unexpected facet.query param: %s", q.params.Get("facet.query"))
+	}
+}
+
+func TestTaggedFilterExcludedFacet(t *testing.T) {
+	q := NewQuery(nil)
+	q.AddTaggedFilter("color", "color", "red")
+	q.AddFacet(&Facet{Field: "color", ExcludeTags: []string{"color"}})
+	if q.params.Get("fq") != "{!tag=color}color:red" {
+		t.Fatalf("unexpected fq param: %s", q.params.Get("fq"))
+	}
+	if q.params.Get("facet.field") != "{!ex=color}color" {
+		t.Fatalf("unexpected facet.field param: %s", q.params.Get("facet.field"))
+	}
+
+	q = NewQuery(nil)
+	q.AddTaggedFilter("main color", "color", "red")
+	q.AddFacet(&Facet{Field: "color", ExcludeTags: []string{"main color", "size}"}})
+	if q.params.Get("fq") != "{!tag='main color'}color:red" {
+		t.Fatalf("unexpected fq param: %s", q.params.Get("fq"))
+	}
+	if q.params.Get("facet.field") != "{!ex='main color,size}'}color" {
+		t.Fatalf("unexpected facet.field param: %s", q.params.Get("facet.field"))
+	}
+}
+
+func TestWriteOptionsDebug(t *testing.T) {
+	opts := &WriteOptions{Debug: true}
+	if opts.formatQueryFromOpts().Get("debug") != "true" {
+		t.Fatal("debug param not registered")
+	}
+}
+
+func TestGeoFilter(t *testing.T) {
+	q := NewQuery(nil)
+	err := q.GeoFilter("store", 91, 0, 5)
+	if err != ErrInvalidCoordinates {
+		t.Fatalf("expected %v but got %v", ErrInvalidCoordinates, err)
+	}
+	err = q.BBoxFilter("store", 45, 0, 0)
+	if err != ErrInvalidDistance {
+		t.Fatalf("expected %v but got %v", ErrInvalidDistance, err)
+	}
+
+	err = q.GeoFilter("store", 45.15, -93.85, 5)
+	if err != nil {
+		t.Fatalf("expected no error but got %s", err)
+	}
+	err = q.BBoxFilter("store", 45.15, -93.85, 2.5)
+	if err != nil {
+		t.Fatalf("expected no error but got %s", err)
+	}
+	actual := q.params["fq"]
+	if len(actual) != 2 || actual[0] != "{!geofilt sfield=store pt=45.15,-93.85 d=5}" || actual[1] != "{!bbox sfield=store pt=45.15,-93.85 d=2.5}" {
+		t.Fatalf("unexpected fq params: %v", actual)
+	}
+}
+
+func TestGeoDist(t *testing.T) {
+	actual, err := GeoDist("store", 45.15, -93.85)
+	if err != nil {
+		t.Fatalf("expected no error but got %s", err)
+	}
+	if actual != "geodist(store,45.15,-93.85)" {
+		t.Fatalf("unexpected function: %s", actual)
+	}
+}
+
+func TestNewQueryWithValidation(t *testing.T) {
+	_, err := NewQueryWithValidation(&ReadOptions{DefType: "test"})
+	if err != ErrInvalidDefType {
+		t.Fatalf("expected %v but got %v", ErrInvalidDefType, err)
+	}
+
+	_, err = NewQueryWithValidation(&ReadOptions{Debug: "debug"})
+	if err != ErrInvalidDebugType {
+		t.Fatalf("expected %v but got %v", ErrInvalidDebugType, err)
+	}
+
+	q, err := NewQueryWithValidation(nil)
+	if err != nil || q == nil {
+		t.Fatalf("expected a query without error but got %v", err)
+	}
+
+	q, err = NewQueryWithValidation(&ReadOptions{Debug: DebugTypeAll, DefType: DefTypeEDisMax, Rows: 5})
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if q.params.Get("defType") != "edismax" || q.params.Get("debug") != "all" || q.params.Get("rows") != "5" {
+		t.Fatalf("unexpected params: %s", q.String())
+	}
+}
+
+func TestSetDefaultOperator(t *testing.T) {
+	q := NewQuery(nil)
+	err := q.SetDefaultOperator("XOR")
+	if err != ErrInvalidOperator {
+		t.Fatalf("expected %v but got %v", ErrInvalidOperator, err)
+	}
+
+	q.SetQuery("genre:horror genre:comedy")
+	err = q.SetDefaultOperator(OperatorAND)
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if q.params.Get("q.op") != "AND" {
+		t.Fatalf("expected q.op=AND but got %s", q.params.Get("q.op"))
+	}
+}
+
+func TestSetOperationWithSetQuery(t *testing.T) {
+	q := NewQuery(nil)
+	q.SetQuery("genre:horror genre:comedy")
+	q.SetOperationAND()
+	q.SetDefaultField("name")
+
+	actual := q.String()
+	if !strings.Contains(actual, "q.op=AND") {
+		t.Fatalf("expected q.op=AND in %s", actual)
+	}
+	if !strings.Contains(actual, "df=name") {
+		t.Fatalf("expected df=name in %s", actual)
+	}
+
+	q.SetOperationOR()
+	if q.params.Get("q.op") != "OR" {
+		t.Fatalf("expected q.op=OR but got %s", q.params.Get("q.op"))
+	}
+}
+
+func TestSetSplitOnWhitespace(t *testing.T) {
+	q := NewQuery(nil)
+	q.SetSplitOnWhitespace(false)
+	if q.params.Get("sow") != "false" {
+		t.Fatalf("expected sow=false but got %s", q.params.Get("sow"))
+	}
+	q.SetSplitOnWhitespace(true)
+	if q.params.Get("sow") != "true" {
+		t.Fatalf("expected sow=true but got %s", q.params.Get("sow"))
+	}
+}
+
+func TestSetTimeAllowed(t *testing.T) {
+	q := NewQuery(nil)
+	q.SetTimeAllowed(500)
+	if q.params.Get("timeAllowed") != "500" {
+		t.Fatalf("expected timeAllowed=500 but got %s", q.params.Get("timeAllowed"))
+	}
+}
+
+func TestAddFacetSingleExcludeTerm(t *testing.T) {
+	q := NewQuery(nil)
+	err := q.AddFacet(&Facet{Field: "genre", ExcludeTerms: []string{"horror"}})
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if q.params.Get("f.genre.facet.excludeTerms") != "horror" {
+		t.Fatalf("unexpected excludeTerms: %s", q.params.Get("f.genre.facet.excludeTerms"))
+	}
+}
+
+func TestSetForceJSON(t *testing.T) {
+	q := NewQuery(nil)
+	q.SetResponseWriter("xml")
+	if q.String() != "wt=json" {
+		t.Fatalf("expected wt to be forced to json but got %s", q.String())
+	}
+
+	q.SetForceJSON(false)
+	q.SetResponseWriter("xml")
+	if q.String() != "wt=xml" {
+		t.Fatalf("expected wt=xml but got %s", q.String())
+	}
+
+	q.DelParam("wt")
+	if q.String() != "" {
+		t.Fatalf("expected no wt param but got %s", q.String())
+	}
+}
+
+func TestExplainDocs(t *testing.T) {
+	q := NewQuery(nil)
+	err := q.ExplainDocs("id", []string{"1", "2"})
+	if err != nil {
+		t.Fatalf("expected no error but got %s", err)
+	}
+	if q.params.Get("debug") != "results" {
+		t.Fatalf("expected debug=results but got %s", q.params.Get("debug"))
+	}
+	if q.params.Get("fq") != "{!terms f=id}1,2" {
+		t.Fatalf("unexpected filter: %s", q.params.Get("fq"))
+	}
+}
+
+func TestAddDateRangeFilter(t *testing.T) {
+	q := NewQuery(nil)
+	err := q.AddDateRangeFilter("created", "", "")
+	if err != ErrInvalidDateRange {
+		t.Fatalf("expected %v but got %v", ErrInvalidDateRange, err)
+	}
+
+	err = q.AddDateRangeFilter("created", "NOW-7DAYS", "NOW")
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	err = q.AddDateRangeFilter("updated", "2020-01-01T00:00:00Z", "")
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	actual := q.params["fq"]
+	if len(actual) != 2 || actual[0] != "created:[NOW-7DAYS TO NOW]" || actual[1] != "updated:[2020-01-01T00:00:00Z TO *]" {
+		t.Fatalf("unexpected fq params: %v", actual)
+	}
+}
+
+func TestWriteOptionsDoNotWaitSearcher(t *testing.T) {
+	opts := &WriteOptions{Commit: true, DoNotWaitSearcher: true}
+	q := opts.formatQueryFromOpts()
+	if q.Get("commit") != "true" || q.Get("waitSearcher") != "false" {
+		t.Fatalf("unexpected params: %s", q.Encode())
+	}
+}
+
+func TestWriteOptionsReturnVersions(t *testing.T) {
+	opts := &WriteOptions{ReturnVersions: true}
+	q := opts.formatQueryFromOpts()
+	if q.Get("versions") != "true" {
+		t.Fatalf("unexpected params: %s", q.Encode())
+	}
+}
+
+func TestAddFieldAlias(t *testing.T) {
+	q := NewQuery(nil)
+	q.AddField("id")
+	q.AddFieldAlias("discount", "product(price,0.9)")
+	actual := q.String()
+	expected := "fl=id&fl=discount%3Aproduct%28price%2C0.9%29&wt=json"
+	if actual != expected {
+		t.Fatalf("expected %s but got %s", expected, actual)
+	}
+
+	vals, err := url.ParseQuery(actual)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fl := vals["fl"]; len(fl) != 2 || fl[1] != "discount:product(price,0.9)" {
+		t.Fatalf("unexpected field list: %v", fl)
+	}
+}
+
+func TestAddFilterWithOptions(t *testing.T) {
+	q := NewQuery(nil)
+	q.AddFilterWithOptions("expensive", "query", &FilterOptions{NoCache: true, Cost: 100})
+	q.AddFilterWithOptions("genre", "horror", &FilterOptions{Tag: "g"})
+	q.AddFilterWithOptions("year", "1968", nil)
+	q.AddFilterWithOptions("year", "1969", &FilterOptions{})
+	q.AddFilterWithOptions("genre", "drama", &FilterOptions{Tag: "main genre", NoCache: true})
+
+	fq := q.params[OptionFilter]
+	expected := []string{"{!cache=false cost=100}expensive:query", "{!tag=g}genre:horror", "year:1968", "year:1969", "{!tag='main genre' cache=false}genre:drama"}
+	if strings.Join(fq, "|") != strings.Join(expected, "|") {
+		t.Fatalf("expected %v but got %v", expected, fq)
+	}
+}
@@ -2,6 +2,11 @@ package solr
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -9,28 +14,188 @@ import (
 // Header information, the response data or an error in case of erroneous
 // response. Also it can contain Debug information when requested, a
 // single document (in the case of realtimeGet) or just a status
-// (in the case of the Ping request)
+// (in the case of the Ping & DataImport requests)
 type Response struct {
-	Header      *ResponseHeader          `json:"responseHeader"`
-	Data        *ResponseData            `json:"response"`
-	Error       *ResponseError           `json:"error"`
-	Debug       *map[string]interface{}  `json:"debug"`
-	Doc         *Doc                     `json:"doc"`
-	Status      *string                  `json:"status"`
-	Expanded    map[string]*ResponseData `json:"expanded"`
-	FacetCounts *FacetCounts             `json:"facet_counts"`
-	Grouped     *Grouped                 `json:"grouped"`
-	Schema      *ResponseSchema          `json:"schema"`
+	Header         *ResponseHeader          `json:"responseHeader"`
+	Data           *ResponseData            `json:"response"`
+	Error          *ResponseError           `json:"error"`
+	Debug          *map[string]interface{}  `json:"debug"`
+	Doc            *Doc                     `json:"doc"`
+	Status         *string                  `json:"status"`
+	Expanded       map[string]*ResponseData `json:"expanded"`
+	FacetCounts    *FacetCounts             `json:"facet_counts"`
+	Grouped        *Grouped                 `json:"grouped"`
+	Schema         *ResponseSchema          `json:"schema"`
+	Similarity     *Similarity              `json:"similarity"`
+	ImportCommand  string                   `json:"command"`
+	ImportResponse string                   `json:"importResponse"`
+	StatusMessages map[string]string        `json:"statusMessages"`
+	Versions       []int64                  `json:"versions"`
+	Highlighting   Highlighting             `json:"highlighting"`
+	Spellcheck     *Spellcheck              `json:"spellcheck"`
+	Suggestions    Suggestions              `json:"suggest"`
+	Stats          *Stats                   `json:"stats"`
+	NextCursorMark string                   `json:"nextCursorMark"`
+	Facets         *JSONFacetResult         `json:"facets"`
+	Terms          Terms                    `json:"terms"`
+	Adds           DocVersions              `json:"adds"`
+	Deletes        DocVersions              `json:"deletes"`
+	// Raw contains the unparsed body of the response, only when
+	// requested with `Client.SetKeepRaw`.
+	Raw []byte `json:"-"`
+	// StatusCode is the HTTP status code of the response
+	StatusCode int `json:"-"`
+}
+
+// IsLastPage reports whether a response to a cursorMark request is the last
+// page of the results. This is the case when the returned nextCursorMark is
+// the same as the cursorMark that was sent with the request. A response without
+// a nextCursorMark (e.g. not a cursor request) is also reported as the last page,
+// since there is no mark to continue from.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/pagination-of-results.html#fetching-a-large-number-of-sorted-results-cursors
+func (r *Response) IsLastPage(prevMark string) bool {
+	return r.NextCursorMark == "" || r.NextCursorMark == prevMark
+}
+
+// Explain returns the score explanation of each returned document mapped by its
+// id, when requested with the results debug type (check `Query.ExplainDocs`).
+func (r *Response) Explain() map[string]string {
+	if r.Debug == nil {
+		return nil
+	}
+	raw, ok := (*r.Debug)["explain"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	explain := make(map[string]string, len(raw))
+	for id, v := range raw {
+		if s, ok := v.(string); ok {
+			explain[id] = s
+		}
+	}
+	return explain
+}
+
+// DebugInfo contains the debug information of a search, as requested with
+// `Query.SetDebug`. Explain contains the score explanation of each returned
+// document mapped by its id, while Timing contains the time spent by each
+// search component (when requested with the timing debug type).
+type DebugInfo struct {
+	RawQueryString string
+	QueryString    string
+	ParsedQuery    string
+	QueryParser    string
+	Explain        map[string]string
+	Timing         map[string]interface{}
+}
+
+// DebugInfo returns the typed debug information of the response,
+// or nil if no debug information was returned by solr.
+func (r *Response) DebugInfo() *DebugInfo {
+	if r.Debug == nil {
+		return nil
+	}
+	debug := *r.Debug
+	info := &DebugInfo{Explain: r.Explain()}
+	info.RawQueryString, _ = debug["rawquerystring"].(string)
+	info.QueryString, _ = debug["querystring"].(string)
+	info.QueryParser, _ = debug["QParser"].(string)
+	info.Timing, _ = debug["timing"].(map[string]interface{})
+	switch parsed := debug["parsedquery"].(type) {
+	case string:
+		info.ParsedQuery = parsed
+	case []interface{}:
+		var queries []string
+		for _, q := range parsed {
+			if q, ok := q.(string); ok {
+				queries = append(queries, q)
+			}
+		}
+		info.ParsedQuery = strings.Join(queries, " ")
+	}
+	return info
+}
+
+// Affected returns the number of documents added or deleted by a write request. It is only
+// available when requested with `WriteOptions.ReturnVersions`, otherwise solr does not
+// report the affected documents and 0 is returned. Solr never reports the documents
+// deleted by a query, which are therefore not included. The deleted documents of every
+// chunk are counted for `DeleteByIDs` requests split in multiple chunks.
+func (r *Response) Affected() int {
+	return len(r.Adds) + len(r.Deletes)
+}
+
+// DocVersion is the id & the version of a document added or deleted by a write request.
+type DocVersion struct {
+	ID      string
+	Version int64
+}
+
+// DocVersions are the documents added or deleted by a write request, which solr
+// returns as a flat list of id & version pairs.
+type DocVersions []DocVersion
+
+// UnmarshalJSON implements the unmarshaler interface. The versions are parsed
+// from the raw values in order to not lose their precision.
+func (v *DocVersions) UnmarshalJSON(b []byte) error {
+	var raw []json.RawMessage
+	err := json.Unmarshal(b, &raw)
+	if err != nil {
+		return err
+	}
+	versions := make(DocVersions, 0, len(raw)/2)
+	for i := 0; i+1 < len(raw); i += 2 {
+		id := string(raw[i])
+		if strings.HasPrefix(id, `"`) {
+			err = json.Unmarshal(raw[i], &id)
+			if err != nil {
+				return err
+			}
+		}
+		version, err := strconv.ParseInt(string(raw[i+1]), 10, 64)
+		if err != nil {
+			return err
+		}
+		versions = append(versions, DocVersion{ID: id, Version: version})
+	}
+	*v = versions
+	return nil
 }
 
 // ResponseHeader is populated on every response from the solr server
 // unless explicitly omitted. It contains the request status code
 // the time it took as well as the params for the search query
-// when applicable
+// when applicable. PartialResults is only set when the search
+// exceeded the time allowed and the results are incomplete.
 type ResponseHeader struct {
-	Status int64                   `json:"status"`
-	QTime  int64                   `json:"QTime"`
-	Params *map[string]interface{} `json:"params"`
+	Status         int64                   `json:"status"`
+	QTime          int64                   `json:"QTime"`
+	Params         *map[string]interface{} `json:"params"`
+	PartialResults bool                    `json:"partialResults,omitempty"`
+}
+
+// UnmarshalJSON implements the unmarshaler interface. The partialResults
+// flag is parsed whether solr returns it as a boolean or as a string.
+func (h *ResponseHeader) UnmarshalJSON(b []byte) error {
+	type alias ResponseHeader
+	temp := struct {
+		PartialResults interface{} `json:"partialResults"`
+		*alias
+	}{alias: (*alias)(h)}
+
+	err := json.Unmarshal(b, &temp)
+	if err != nil {
+		return err
+	}
+
+	switch v := temp.PartialResults.(type) {
+	case bool:
+		h.PartialResults = v
+	case string:
+		h.PartialResults, _ = strconv.ParseBool(v)
+	}
+	return nil
 }
 
 // ResponseData is populated on a successful response from the solr
@@ -44,8 +209,10 @@ type ResponseData struct {
 }
 
 // MaxScore is used as a struct due to the fact that solr
-// may return it as a float or as a string indicating
-// "NaN"
+// may return it as a number or as a string, e.g. "NaN"
+// when no documents were scored, in which case Score
+// is NaN. Valid is false only when the value is not
+// a number at all.
 type MaxScore struct {
 	Valid bool
 	Score float64
@@ -58,13 +225,7 @@ func (m *MaxScore) UnmarshalJSON(b []byte) error {
 	if err != nil {
 		return err
 	}
-	switch v := i.(type) {
-	case float64:
-		m.Score = v
-		m.Valid = true
-	default:
-		m.Valid = false
-	}
+	m.Score, m.Valid = toFloat64(i)
 	return nil
 }
 
@@ -84,6 +245,87 @@ func (d *Doc) ToBytes() ([]byte, error) {
 	return interfaceToBytes(d)
 }
 
+// Unmarshal stores the documents in the value pointed to by v, which is
+// usually a pointer to a slice of structs, e.g. `*[]*Film`.
+func (d Docs) Unmarshal(v interface{}) error {
+	b, err := d.ToBytes()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// Unmarshal stores the document in the value pointed to by v, which is
+// usually a pointer to a struct, e.g. `*Film`.
+func (d *Doc) Unmarshal(v interface{}) error {
+	b, err := d.ToBytes()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// ErrInvalidChildDocuments is returned when the child documents of a document
+// are not an array of documents
+var ErrInvalidChildDocuments = errors.New("invalid child documents, expected an array of documents")
+
+// Children returns the nested child documents of the document, which solr returns
+// when the child doc transformer is requested (check `ChildTransformer`). An
+// empty Docs is returned when the document has no children.
+func (d *Doc) Children() (Docs, error) {
+	children := Docs{}
+	if d == nil {
+		return children, nil
+	}
+	raw, ok := (*d)["_childDocuments_"]
+	if !ok || raw == nil {
+		return children, nil
+	}
+	items, ok := raw.([]interface{})
+	if !ok {
+		return nil, ErrInvalidChildDocuments
+	}
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, ErrInvalidChildDocuments
+		}
+		child := Doc(m)
+		children = append(children, &child)
+	}
+	return children, nil
+}
+
+// Score returns the score of the document, if it was requested
+// (check `Query.IncludeScore`) and returned by solr.
+func (d Doc) Score() (float64, bool) {
+	return toFloat64(d["score"])
+}
+
+// Features returns the feature values of the document as extracted by the LTR
+// plugin, if they were requested (check `Query.IncludeFeatures`). Solr
+// returns them in the `name1=value1,name2=value2` format. Malformed pairs are
+// skipped.
+func (d Doc) Features() (map[string]float64, bool) {
+	raw, ok := d["[features]"].(string)
+	if !ok {
+		return nil, false
+	}
+	features := map[string]float64{}
+	for _, pair := range strings.Split(raw, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		v, err := strconv.ParseFloat(kv[1], 64)
+		if err != nil {
+			continue
+		}
+		features[kv[0]] = v
+	}
+	return features, true
+}
+
 // FacetCounts is populated whenever the query to solr includes facets.
 // Each of the following attributes get populated depending on the
 // actual facet query. 'Fields' attribute includes a helper to
@@ -124,7 +366,7 @@ func (f *FacetFields) UnmarshalJSON(b []byte) error {
 		values := map[string]float64{}
 		for i := 0; i < len(v); i += 2 {
 			s, ok := v[i].(string)
-			n, ok2 := v[i+1].(float64)
+			n, ok2 := toFloat64(v[i+1])
 			if ok && ok2 {
 				values[s] = n
 			}
@@ -148,19 +390,148 @@ type Pivot struct {
 	Ranges  map[string]*Range `json:"ranges"`
 }
 
-// Range contains range faceting results.
+// Range contains range faceting results. Start, End & Gap are either numbers
+// or dates (as strings) depending on the type of the faceted field (check
+// `StartTime`, `StartFloat` & `GapString` for typed access).
 // More info:
 // https://lucene.apache.org/solr/guide/8_5/faceting.html#range-faceting
 type Range struct {
-	Counts *FacetFields `json:"counts"`
-	Gap    string       `json:"gap"`
-	Start  time.Time    `json:"start"`
-	End    time.Time    `json:"end"`
+	Counts  *RangeCounts `json:"counts"`
+	Gap     interface{}  `json:"gap"`
+	Start   interface{}  `json:"start"`
+	End     interface{}  `json:"end"`
+	Before  int          `json:"before"`
+	After   int          `json:"after"`
+	Between int          `json:"between"`
 }
 
-// Stats containts the results of stats when requested during pivot faceting.
+// StartTime returns the start of a date range facet.
+func (r *Range) StartTime() (time.Time, bool) {
+	return toTime(r.Start)
+}
+
+// EndTime returns the end of a date range facet.
+func (r *Range) EndTime() (time.Time, bool) {
+	return toTime(r.End)
+}
+
+// StartFloat returns the start of a numeric range facet.
+func (r *Range) StartFloat() (float64, bool) {
+	return toFloat64(r.Start)
+}
+
+// EndFloat returns the end of a numeric range facet.
+func (r *Range) EndFloat() (float64, bool) {
+	return toFloat64(r.End)
+}
+
+// GapString returns the gap of the range facet as sent by solr, e.g.
+// `+1YEAR` for date ranges or `10` for numeric ones.
+func (r *Range) GapString() string {
+	switch gap := r.Gap.(type) {
+	case string:
+		return gap
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(gap)
+	}
+}
+
+// RangeCounts contains the counts of a range facet. Solr returns them in an
+// array that alternates between the range start and the count, which is
+// parsed into a map of range start to count.
+type RangeCounts struct {
+	m map[string]float64
+}
+
+// Get returns the count of the range starting at the given value.
+func (r *RangeCounts) Get(s string) float64 {
+	return r.m[s]
+}
+
+// Map returns the counts of all the ranges, mapped by the range start.
+func (r *RangeCounts) Map() map[string]float64 {
+	return r.m
+}
+
+// UnmarshalJSON implements the unmarshaler interface.
+func (r *RangeCounts) UnmarshalJSON(b []byte) error {
+	r.m = make(map[string]float64)
+	var temp []interface{}
+	err := json.Unmarshal(b, &temp)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i+1 < len(temp); i += 2 {
+		s, ok := temp[i].(string)
+		n, ok2 := toFloat64(temp[i+1])
+		if ok && ok2 {
+			r.m[s] = n
+		}
+	}
+	return nil
+}
+
+// Stats contains the results of the stats component, either for the whole
+// result set or for each pivot when requested during pivot faceting.
 type Stats struct {
-	Fields map[string]interface{} `json:"stats_fields"`
+	Fields map[string]*FieldStats `json:"stats_fields"`
+}
+
+// FieldStats contains the statistics computed for a single field. Each
+// statistic may be absent depending on the request and on the type
+// of the field, e.g. Min & Max of a string field are not numbers
+// and are therefore omitted.
+type FieldStats struct {
+	Min     *float64
+	Max     *float64
+	Count   *float64
+	Missing *float64
+	Sum     *float64
+	Mean    *float64
+	Stddev  *float64
+	Facets  map[string]map[string]*FieldStats
+}
+
+// UnmarshalJSON implements the unmarshaler interface.
+func (f *FieldStats) UnmarshalJSON(b []byte) error {
+	var m map[string]json.RawMessage
+	err := json.Unmarshal(b, &m)
+	if err != nil {
+		return err
+	}
+
+	stats := map[string]**float64{
+		"min":     &f.Min,
+		"max":     &f.Max,
+		"count":   &f.Count,
+		"missing": &f.Missing,
+		"sum":     &f.Sum,
+		"mean":    &f.Mean,
+		"stddev":  &f.Stddev,
+	}
+	for key, target := range stats {
+		raw, ok := m[key]
+		if !ok {
+			continue
+		}
+		var v float64
+		if json.Unmarshal(raw, &v) == nil {
+			*target = &v
+		}
+	}
+
+	facets, ok := m["facets"]
+	if ok {
+		err = json.Unmarshal(facets, &f.Facets)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // Grouped contains the groups that are returned when result grouping is on.
@@ -213,6 +584,23 @@ func (g *Grouped) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// Field returns the groups created by the given field or function,
+// or nil if there are none.
+func (g *Grouped) Field(name string) *GroupField {
+	if g == nil {
+		return nil
+	}
+	return g.ByFieldOrFunc[name]
+}
+
+// Query returns the group created by the given query, or nil if there is none.
+func (g *Grouped) Query(query string) *Group {
+	if g == nil {
+		return nil
+	}
+	return g.ByQuery[query]
+}
+
 // GroupField is populated whenever the query to solr includes grouping.
 // The response contains the total matches (of docs), the number of
 // groups (if requested) and the groups.
@@ -230,6 +618,27 @@ type Group struct {
 	DocList *ResponseData `json:"doclist"`
 }
 
+// ValueString returns the value of the group as a string, regardless of the type
+// of the field or function the groups were created by. The group of the documents
+// without a value (which solr returns as null) has an empty value.
+func (g *Group) ValueString() string {
+	switch v := g.Value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
 // ResponseSchema is populated when using the Schema API to retrive
 // schema information.
 type ResponseSchema struct {
@@ -241,3 +650,114 @@ type ResponseSchema struct {
 	CopyFields    []*CopyField    `json:"copyFields"`
 	DynamicFields []*DynamicField `json:"dynamicFields"`
 }
+
+// FieldTypeByName returns the field type with the given name or
+// ErrFieldTypeNotFound if it's not part of the schema.
+func (s *ResponseSchema) FieldTypeByName(name string) (*FieldType, error) {
+	if s != nil {
+		for _, ft := range s.FieldTypes {
+			if ft.Name == name {
+				return ft, nil
+			}
+		}
+	}
+	return nil, ErrFieldTypeNotFound
+}
+
+// FieldByName returns the field with the given name or
+// ErrFieldNotFound if it's not part of the schema.
+func (s *ResponseSchema) FieldByName(name string) (*Field, error) {
+	if s != nil {
+		for _, fl := range s.Fields {
+			if fl.Name == name {
+				return fl, nil
+			}
+		}
+	}
+	return nil, ErrFieldNotFound
+}
+
+// DynamicFieldByName returns the dynamic field with the given name or
+// ErrDynamicFieldNotFound if it's not part of the schema.
+func (s *ResponseSchema) DynamicFieldByName(name string) (*DynamicField, error) {
+	if s != nil {
+		for _, df := range s.DynamicFields {
+			if df.Name == name {
+				return df, nil
+			}
+		}
+	}
+	return nil, ErrDynamicFieldNotFound
+}
+
+// ValidateDoc checks the given document against the schema before indexing it, returning
+// the problems found: fields that are neither defined nor match a dynamic field and
+// required fields (without a default value) that are missing. The problems are
+// sorted by field name and nil is returned for a valid document.
+func (s *ResponseSchema) ValidateDoc(doc map[string]interface{}) []FieldError {
+	var problems []FieldError
+	if s == nil {
+		return problems
+	}
+
+	for name := range doc {
+		if !s.hasField(name) {
+			problems = append(problems, FieldError{Field: name, Err: ErrUnknownField})
+		}
+	}
+	for _, fl := range s.Fields {
+		if fl.Required == nil || !*fl.Required || fl.Default != nil {
+			continue
+		}
+		if _, ok := doc[fl.Name]; !ok {
+			problems = append(problems, FieldError{Field: fl.Name, Err: ErrRequiredFieldMissing})
+		}
+	}
+
+	sort.Slice(problems, func(i, j int) bool {
+		return problems[i].Field < problems[j].Field
+	})
+	return problems
+}
+
+// hasField reports whether the given field name is defined in the schema, either
+// as a field or by matching the pattern of a dynamic field.
+func (s *ResponseSchema) hasField(name string) bool {
+	_, err := s.FieldByName(name)
+	if err == nil {
+		return true
+	}
+	for _, df := range s.DynamicFields {
+		if matchDynamicField(df.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchDynamicField reports whether the name matches the pattern of a dynamic field,
+// which may contain a single wildcard either at its start or at its end.
+func matchDynamicField(pattern, name string) bool {
+	switch {
+	case pattern == "*":
+		return true
+	case strings.HasPrefix(pattern, "*"):
+		return strings.HasSuffix(name, pattern[1:])
+	case strings.HasSuffix(pattern, "*"):
+		return strings.HasPrefix(name, pattern[:len(pattern)-1])
+	}
+	return pattern == name
+}
+
+// CopyFieldBySourceDest returns the copy field rule with the given source and
+// destination or ErrCopyFieldNotFound if it's not part of the schema.
+func (s *ResponseSchema) CopyFieldBySourceDest(source, dest string) (*CopyField, error) {
+	if s != nil {
+		for _, cf := range s.CopyFields {
+			if cf.Source == source && cf.Dest == dest {
+				return cf, nil
+			}
+		}
+	}
+	return nil, ErrCopyFieldNotFound
+}
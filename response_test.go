@@ -0,0 +1,415 @@
+package solr
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestStatsUnmarshal(t *testing.T) {
+	input := `{"stats":{"stats_fields":{
+		"price":{"min":1.5,"max":10.0,"count":3,"missing":0,"sum":15.0,"mean":5.0,"stddev":2.5,
+			"facets":{"genre":{"scifi":{"min":1.5,"max":1.5,"count":1}}}},
+		"name":{"min":"Alien","max":"Heat","count":3,"missing":0}}}}`
+	var res Response
+	err := json.Unmarshal([]byte(input), &res)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	price := res.Stats.Fields["price"]
+	if *price.Min != 1.5 || *price.Max != 10 || *price.Count != 3 || *price.Mean != 5 {
+		t.Fatalf("unexpected price stats: %+v", price)
+	}
+	if *price.Facets["genre"]["scifi"].Count != 1 {
+		t.Fatal("unexpected faceted stats")
+	}
+
+	name := res.Stats.Fields["name"]
+	if name.Min != nil || name.Max != nil || *name.Count != 3 {
+		t.Fatalf("unexpected name stats: %+v", name)
+	}
+}
+
+func TestRangeFacetUnmarshal(t *testing.T) {
+	input := `{"facet_counts":{"facet_ranges":{"year":{"counts":["1970",2,"1980",1],"gap":10,"start":1970,"end":1990,"before":1}}}}`
+	var res Response
+	err := json.Unmarshal([]byte(input), &res)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	year := res.FacetCounts.Ranges["year"]
+	if year.Counts.Get("1970") != 2 || year.Counts.Get("1980") != 1 {
+		t.Fatalf("unexpected range counts: %v", year.Counts.Map())
+	}
+	if year.Start != float64(1970) || year.Before != 1 {
+		t.Fatalf("unexpected range: %+v", year)
+	}
+	start, ok := year.StartFloat()
+	end, ok2 := year.EndFloat()
+	if !ok || !ok2 || start != 1970 || end != 1990 || year.GapString() != "10" {
+		t.Fatalf("unexpected typed range values: %v, %v, %s", start, end, year.GapString())
+	}
+	if _, ok := year.StartTime(); ok {
+		t.Fatal("a numeric range should not have a start time")
+	}
+
+	input = `{"facet_counts":{"facet_ranges":{"released":{"counts":["2020-01-01T00:00:00Z",4],
+		"gap":"+1YEAR","start":"2020-01-01T00:00:00Z","end":"2021-01-01T00:00:00Z"}}}}`
+	res = Response{}
+	err = json.Unmarshal([]byte(input), &res)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	released := res.FacetCounts.Ranges["released"]
+	startTime, ok := released.StartTime()
+	endTime, ok2 := released.EndTime()
+	if !ok || !ok2 || startTime.Year() != 2020 || endTime.Year() != 2021 || released.GapString() != "+1YEAR" {
+		t.Fatalf("unexpected typed range values: %s, %s, %s", startTime, endTime, released.GapString())
+	}
+}
+
+func TestDecodeResponseTolerance(t *testing.T) {
+	input := `{"response":{"numFound":2,"start":0,"maxScore":"NaN","docs":[
+		{"id":"1","score":1.5,"_version_":1681234567890123456},
+		{"id":"2","score":"0.5"}]}}`
+
+	res, err := decodeResponse(http.StatusOK, strings.NewReader(input), true, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Data.NumFound != 2 || res.Data.Start != 0 {
+		t.Fatalf("unexpected numFound/start: %d/%d", res.Data.NumFound, res.Data.Start)
+	}
+	if !res.Data.MaxScore.Valid || !math.IsNaN(res.Data.MaxScore.Score) {
+		t.Fatalf("expected a valid NaN maxScore but got %+v", res.Data.MaxScore)
+	}
+
+	doc := res.Data.Docs[0]
+	if v, ok := (*doc)["_version_"].(json.Number); !ok || v.String() != "1681234567890123456" {
+		t.Fatalf("expected version to keep its precision but got %v", (*doc)["_version_"])
+	}
+	if score, ok := doc.Score(); !ok || score != 1.5 {
+		t.Fatalf("unexpected score: %v", score)
+	}
+	if score, ok := res.Data.Docs[1].Score(); !ok || score != 0.5 {
+		t.Fatalf("unexpected score: %v", score)
+	}
+
+	res, err = decodeResponse(http.StatusOK, strings.NewReader(`{"response":{"numFound":1,"maxScore":2.5,"docs":[]}}`), false, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Data.NumFound != 1 || !res.Data.MaxScore.Valid || res.Data.MaxScore.Score != 2.5 {
+		t.Fatalf("unexpected response data: %+v", res.Data)
+	}
+}
+
+func TestPartialResults(t *testing.T) {
+	inputs := map[string]bool{
+		`{"responseHeader":{"status":0,"QTime":501,"partialResults":true}}`:   true,
+		`{"responseHeader":{"status":0,"QTime":501,"partialResults":"true"}}`: true,
+		`{"responseHeader":{"status":0,"QTime":5}}`:                           false,
+	}
+	for input, expected := range inputs {
+		var res Response
+		err := json.Unmarshal([]byte(input), &res)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.Header.PartialResults != expected {
+			t.Fatalf("expected partialResults %v for %s", expected, input)
+		}
+		if res.Header.Status != 0 || res.Header.QTime == 0 {
+			t.Fatalf("expected the rest of the header to be parsed for %s", input)
+		}
+	}
+}
+
+func TestResponseExplain(t *testing.T) {
+	var res Response
+	if res.Explain() != nil {
+		t.Fatal("expected no explain without debug info")
+	}
+
+	input := `{"debug":{"explain":{"1":"\n1.5 = weight(name:alien in 0)","2":"\n0.5 = weight(name:alien in 1)"}}}`
+	err := json.Unmarshal([]byte(input), &res)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	explain := res.Explain()
+	if len(explain) != 2 || explain["1"] != "\n1.5 = weight(name:alien in 0)" {
+		t.Fatalf("unexpected explain: %v", explain)
+	}
+}
+
+func TestDocChildren(t *testing.T) {
+	input := `{"response":{"numFound":2,"docs":[
+		{"id":"order1","_childDocuments_":[{"id":"item1","sku":"123"},{"id":"item2","sku":"456"}]},
+		{"id":"order2"}]}}`
+	var res Response
+	err := json.Unmarshal([]byte(input), &res)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	children, err := res.Data.Docs[0].Children()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(children) != 2 || (*children[1])["sku"] != "456" {
+		t.Fatalf("unexpected children: %v", children)
+	}
+
+	children, err = res.Data.Docs[1].Children()
+	if err != nil || children == nil || len(children) != 0 {
+		t.Fatalf("expected empty children without error but got %v, %v", children, err)
+	}
+
+	invalid := Doc{"_childDocuments_": "item1"}
+	_, err = invalid.Children()
+	if err != ErrInvalidChildDocuments {
+		t.Fatalf("expected %v but got %v", ErrInvalidChildDocuments, err)
+	}
+}
+
+func TestDocsUnmarshal(t *testing.T) {
+	type film struct {
+		ID   string `json:"id"`
+		Year int    `json:"year"`
+	}
+
+	docs := Docs{&Doc{"id": "1", "year": 1979.0}, &Doc{"id": "2", "year": 1986.0}}
+	var films []*film
+	err := docs.Unmarshal(&films)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(films) != 2 || films[1].ID != "2" || films[1].Year != 1986 {
+		t.Fatalf("unexpected films: %v", films)
+	}
+
+	var f film
+	err = docs[0].Unmarshal(&f)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.ID != "1" || f.Year != 1979 {
+		t.Fatalf("unexpected film: %v", f)
+	}
+}
+
+func TestDecodeResponseStatusCode(t *testing.T) {
+	res, err := decodeResponse(http.StatusOK, strings.NewReader(`{"responseHeader":{"status":0}}`), false, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d but got %d", http.StatusOK, res.StatusCode)
+	}
+
+	res, err = decodeResponse(http.StatusNoContent, strings.NewReader(""), false, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected status %d but got %d", http.StatusNoContent, res.StatusCode)
+	}
+}
+
+func TestGroupedUnmarshalMixed(t *testing.T) {
+	input := `{"grouped":{
+		"genre":{"matches":3,"ngroups":2,"groups":[
+			{"groupValue":"horror","doclist":{"numFound":2,"start":0,"docs":[{"id":"1"}]}},
+			{"groupValue":"comedy","doclist":{"numFound":1,"start":0,"docs":[{"id":"3"}]}}]},
+		"year:[1980 TO *]":{"matches":3,"doclist":{"numFound":2,"start":0,"docs":[{"id":"2"}]}},
+		"div(year,10)":{"matches":3,"groups":[
+			{"groupValue":197.9,"doclist":{"numFound":1,"start":0,"docs":[{"id":"1"}]}}]}}}`
+	var res Response
+	err := json.Unmarshal([]byte(input), &res)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	genre := res.Grouped.ByFieldOrFunc["genre"]
+	if genre == nil || genre.NumberOfGroups != 2 || genre.Groups[0].Value != "horror" {
+		t.Fatalf("unexpected field groups: %+v", genre)
+	}
+	fn := res.Grouped.ByFieldOrFunc["div(year,10)"]
+	if fn == nil || len(fn.Groups) != 1 || fn.Groups[0].DocList.NumFound != 1 {
+		t.Fatalf("unexpected func groups: %+v", fn)
+	}
+	query := res.Grouped.ByQuery["year:[1980 TO *]"]
+	if query == nil || query.Matches != 3 || query.DocList.NumFound != 2 {
+		t.Fatalf("unexpected query groups: %+v", query)
+	}
+}
+
+func TestGroupedHelpers(t *testing.T) {
+	input := `{"grouped":{
+		"year":{"matches":3,"ngroups":3,"groups":[
+			{"groupValue":1968,"doclist":{"numFound":1,"start":0,"docs":[{"id":"1"}]}},
+			{"groupValue":null,"doclist":{"numFound":1,"start":0,"docs":[{"id":"2"}]}}]},
+		"genre":{"matches":3,"groups":[
+			{"groupValue":"horror","doclist":{"numFound":2,"start":0,"docs":[{"id":"1"}]}}]},
+		"year:1968":{"matches":3,"doclist":{"numFound":1,"start":0,"docs":[{"id":"1"}]}}}}`
+	var res Response
+	err := json.Unmarshal([]byte(input), &res)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	years := res.Grouped.Field("year")
+	if years == nil || years.Groups[0].ValueString() != "1968" || years.Groups[1].ValueString() != "" {
+		t.Fatalf("unexpected year groups: %+v", years)
+	}
+	if v := res.Grouped.Field("genre").Groups[0].ValueString(); v != "horror" {
+		t.Fatalf("unexpected genre group value: %s", v)
+	}
+	if q := res.Grouped.Query("year:1968"); q == nil || q.DocList.NumFound != 1 {
+		t.Fatalf("unexpected query group: %+v", q)
+	}
+	if res.Grouped.Field("missing") != nil || res.Grouped.Query("missing") != nil {
+		t.Fatal("expected no groups for missing keys")
+	}
+
+	var empty Response
+	if empty.Grouped.Field("year") != nil || empty.Grouped.Query("year:1968") != nil {
+		t.Fatal("expected no groups without grouping")
+	}
+}
+
+func TestAffected(t *testing.T) {
+	payload := `{"responseHeader":{"status":0,"QTime":3},
+		"adds":["1",1680859137034584064,2,1680859137036681216],
+		"deletes":["3",-1680859137037729792]}`
+	var res Response
+	err := json.Unmarshal([]byte(payload), &res)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Affected() != 3 {
+		t.Fatalf("expected 3 affected documents but got %d", res.Affected())
+	}
+	if res.Adds[0].ID != "1" || res.Adds[0].Version != 1680859137034584064 {
+		t.Fatalf("unexpected add: %+v", res.Adds[0])
+	}
+	if res.Adds[1].ID != "2" || res.Adds[1].Version != 1680859137036681216 {
+		t.Fatalf("unexpected add: %+v", res.Adds[1])
+	}
+	if res.Deletes[0].ID != "3" || res.Deletes[0].Version != -1680859137037729792 {
+		t.Fatalf("unexpected delete: %+v", res.Deletes[0])
+	}
+
+	var empty Response
+	err = json.Unmarshal([]byte(`{"responseHeader":{"status":0,"QTime":3}}`), &empty)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if empty.Affected() != 0 {
+		t.Fatalf("expected no affected documents but got %d", empty.Affected())
+	}
+}
+
+func TestDebugInfo(t *testing.T) {
+	payload := `{"responseHeader":{"status":0,"QTime":4},
+		"response":{"numFound":1,"start":0,"docs":[{"id":"1","name":"The Matrix"}]},
+		"debug":{
+			"rawquerystring":"name:matrix",
+			"querystring":"name:matrix",
+			"parsedquery":"name:matrix",
+			"parsedquery_toString":"name:matrix",
+			"explain":{"1":"\n1.3862942 = weight(name:matrix in 0) [SchemaSimilarity], result of:\n"},
+			"QParser":"LuceneQParser",
+			"timing":{"time":1.0,"prepare":{"time":0.0},"process":{"time":1.0}}}}`
+
+	var res Response
+	err := json.Unmarshal([]byte(payload), &res)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	info := res.DebugInfo()
+	if info == nil {
+		t.Fatal("expected the debug info to be parsed")
+	}
+	if info.RawQueryString != "name:matrix" || info.QueryString != "name:matrix" || info.ParsedQuery != "name:matrix" {
+		t.Fatalf("unexpected queries: %+v", info)
+	}
+	if info.QueryParser != "LuceneQParser" {
+		t.Fatalf("unexpected query parser: %s", info.QueryParser)
+	}
+	if !strings.Contains(info.Explain["1"], "weight(name:matrix in 0)") {
+		t.Fatalf("unexpected explain: %v", info.Explain)
+	}
+	if info.Timing["time"] != 1.0 {
+		t.Fatalf("unexpected timing: %v", info.Timing)
+	}
+
+	var noDebug Response
+	if noDebug.DebugInfo() != nil {
+		t.Fatal("expected no debug info")
+	}
+}
+
+func TestMaxScore(t *testing.T) {
+	inputs := map[string]float64{
+		`1.25`:  1.25,
+		`3`:     3,
+		`"2.5"`: 2.5,
+	}
+	for input, expected := range inputs {
+		var m MaxScore
+		err := json.Unmarshal([]byte(input), &m)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", input, err)
+		}
+		if !m.Valid || m.Score != expected {
+			t.Fatalf("expected %v for %s but got %+v", expected, input, m)
+		}
+	}
+
+	var m MaxScore
+	err := json.Unmarshal([]byte(`"NaN"`), &m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !m.Valid || !math.IsNaN(m.Score) {
+		t.Fatalf("expected a valid NaN score but got %+v", m)
+	}
+
+	m = MaxScore{}
+	err = json.Unmarshal([]byte(`null`), &m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Valid {
+		t.Fatalf("expected an invalid score but got %+v", m)
+	}
+}
+
+func TestDocFeatures(t *testing.T) {
+	doc := Doc{"id": "1", "[features]": "f1=0.5,f2=1.0"}
+	features, ok := doc.Features()
+	if !ok {
+		t.Fatal("expected the features to be found")
+	}
+	if len(features) != 2 || features["f1"] != 0.5 || features["f2"] != 1.0 {
+		t.Fatalf("unexpected features: %v", features)
+	}
+
+	_, ok = Doc{"id": "1"}.Features()
+	if ok {
+		t.Fatal("expected no features when they were not requested")
+	}
+
+	doc = Doc{"id": "1", "[features]": "f1=0.5,f2,f3=abc,f4=2"}
+	features, ok = doc.Features()
+	if !ok {
+		t.Fatal("expected the features to be found")
+	}
+	if len(features) != 2 || features["f1"] != 0.5 || features["f4"] != 2 {
+		t.Fatalf("expected the malformed pairs to be skipped but got %v", features)
+	}
+}
@@ -1,10 +1,14 @@
 package solr
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
+	"sync"
 )
 
 // Valid commands for the schema API
@@ -28,8 +32,29 @@ var (
 	ErrFieldTypeNotFound    = errors.New("field type not found")
 	ErrDynamicFieldNotFound = errors.New("dynamic field not found")
 	ErrCopyFieldNotFound    = errors.New("copy field not found")
+	ErrRequiredFieldMissing = errors.New("required field missing")
+	ErrEmptySchemaBatch     = errors.New("the schema batch contains no commands")
+	ErrNoCopyFieldDest      = errors.New("no copy field destinations provided")
+	ErrNoFields             = errors.New("no fields provided")
 )
 
+// FieldError is a problem of a document with a specific field, as found when
+// validating the document against the schema (check `ValidateDoc`). Err is
+// either ErrUnknownField or ErrRequiredFieldMissing.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Err)
+}
+
+// Unwrap returns the underlying error, allowing the use of `errors.Is`.
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
 // Analyzer represents the analyzer entity. An analyzer examines the text of
 // fields and generates a token stream. For more info:
 // https://lucene.apache.org/solr/guide/8_5/analyzers.html
@@ -39,20 +64,22 @@ type Analyzer struct {
 }
 
 // FieldType represents a solr field type. A field type defines the analysis that will occur on a field when documents
-// are indexed or queries are sent to the index. Only a name and the class name are mandatory. For more info:
+// are indexed or queries are sent to the index. Only a name and the class name are mandatory. Similarity sets
+// the similarity used for the fields of this type (e.g. BM25 with specific k1 & b). For more info:
 // https://lucene.apache.org/solr/guide/8_5/field-type-definitions-and-properties.html#general-properties
 type FieldType struct {
-	Name                      string    `json:"name"`
-	CLass                     string    `json:"class"`
-	PositionIncrementGap      string    `json:"positionIncrementGap,omitempty"`
-	AutoGeneratePhraseQueries string    `json:"autoGeneratePhraseQueries,omitempty"`
-	SynonymQueryStyle         string    `json:"synonymQueryStyle,omitempty"`
-	EnableGraphQueries        bool      `json:"enableGraphQueries,omitempty"`
-	DocValuesFormat           string    `json:"docValuesFormat,omitempty"`
-	PostingsFormat            string    `json:"postingsFormat,omitempty"`
-	Analyzer                  *Analyzer `json:"analyzer,omitempty"`
-	IndexAnalyzer             *Analyzer `json:"indexAnalyzer,omitempty"`
-	QueryAnalyzer             *Analyzer `json:"queryAnalyzer,omitempty"`
+	Name                      string      `json:"name"`
+	CLass                     string      `json:"class"`
+	PositionIncrementGap      string      `json:"positionIncrementGap,omitempty"`
+	AutoGeneratePhraseQueries string      `json:"autoGeneratePhraseQueries,omitempty"`
+	SynonymQueryStyle         string      `json:"synonymQueryStyle,omitempty"`
+	EnableGraphQueries        bool        `json:"enableGraphQueries,omitempty"`
+	DocValuesFormat           string      `json:"docValuesFormat,omitempty"`
+	PostingsFormat            string      `json:"postingsFormat,omitempty"`
+	Analyzer                  *Analyzer   `json:"analyzer,omitempty"`
+	IndexAnalyzer             *Analyzer   `json:"indexAnalyzer,omitempty"`
+	QueryAnalyzer             *Analyzer   `json:"queryAnalyzer,omitempty"`
+	Similarity                *Similarity `json:"similarity,omitempty"`
 	FieldDefaultProperties
 }
 
@@ -100,10 +127,62 @@ type CopyField struct {
 	MaxChars int    `json:"maxChars,omitempty"`
 }
 
+// copyFieldMulti is a copy field rule with multiple destinations, which
+// is only used for adding them, since solr stores a rule per destination.
+type copyFieldMulti struct {
+	Source   string   `json:"source"`
+	Dest     []string `json:"dest"`
+	MaxChars int      `json:"maxChars,omitempty"`
+}
+
 // DynamicField is just like a regular field except it has a name with a wildcard in it.
 // For more info: https://lucene.apache.org/solr/guide/8_5/dynamic-fields.html
 type DynamicField Field
 
+// Similarity represents a similarity factory, the class used to score documents in
+// a search. Any parameters of the factory (e.g. k1 & b for BM25) are contained
+// in Params and are flattened alongside the class when (un)marshaling.
+// For more info:
+// https://lucene.apache.org/solr/guide/8_5/other-schema-elements.html#similarity
+type Similarity struct {
+	Class  string
+	Params map[string]interface{}
+}
+
+// MarshalJSON implements the marshaler interface
+func (s *Similarity) MarshalJSON() ([]byte, error) {
+	m := make(map[string]interface{}, len(s.Params)+1)
+	for k, v := range s.Params {
+		m[k] = v
+	}
+	m["class"] = s.Class
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON implements the unmarshaler interface
+func (s *Similarity) UnmarshalJSON(b []byte) error {
+	var m map[string]interface{}
+	err := json.Unmarshal(b, &m)
+	if err != nil {
+		return err
+	}
+
+	for key, val := range m {
+		if key == "class" {
+			class, ok := val.(string)
+			if ok {
+				s.Class = class
+			}
+			continue
+		}
+		if s.Params == nil {
+			s.Params = make(map[string]interface{})
+		}
+		s.Params[key] = val
+	}
+	return nil
+}
+
 // SchemaCommand is used to restrict the available update commands that can
 // be included in the body of a s.conn.request to the `/update` endpoint.
 type SchemaCommand string
@@ -114,6 +193,14 @@ func (c SchemaCommand) String() string {
 
 type schemaBuilder struct {
 	commands map[SchemaCommand]interface{}
+	actions  []*schemaAction
+}
+
+// schemaAction is a single command of a batch, which keeps its
+// position in the body.
+type schemaAction struct {
+	command SchemaCommand
+	value   interface{}
 }
 
 func newSchemaBuilder() *schemaBuilder {
@@ -129,14 +216,52 @@ func (b *schemaBuilder) del(command SchemaCommand, name string) {
 	b.commands[command] = map[string]string{"name": name}
 }
 
+func (b *schemaBuilder) delMany(command SchemaCommand, names []string) {
+	items := make([]map[string]string, 0, len(names))
+	for _, name := range names {
+		items = append(items, map[string]string{"name": name})
+	}
+	b.commands[command] = items
+}
+
 func (b *schemaBuilder) delCopyField(source, dest string) {
 	b.commands[SchemaCommandDeleteCopyField] = map[string]string{"source": source, "dest": dest}
 }
 
+// append adds a separate command, which is sent in the order it was inserted.
+func (b *schemaBuilder) append(command SchemaCommand, item interface{}) {
+	b.actions = append(b.actions, &schemaAction{command: command, value: item})
+}
+
+// marshal returns the body of the request, writing the commands in the order they
+// were inserted, since solr applies them in the order they appear in the body.
+// Since the commands may repeat a command name, the body is written by hand.
+func (b *schemaBuilder) marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for _, action := range b.actions {
+		value, err := interfaceToBytes(action.value)
+		if err != nil {
+			return nil, err
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(`"` + action.command.String() + `":`)
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
 // SchemaAPI contains a connection to solr and the path to it.
 type SchemaAPI struct {
-	conn *Connection
-	Path string
+	conn   *Connection
+	Path   string
+	mu     sync.Mutex
+	cached bool
+	schema []byte
+	gen    uint64
 }
 
 // NewSchemaAPI returns a new schema API, creating a connection to solr using the provided
@@ -166,19 +291,137 @@ func (s *SchemaAPI) SetBasicAuth(username, password string) {
 	s.conn.Password = password
 }
 
+// SetOnRequest sets a hook that is called after each request with information
+// about it, e.g. in order to record metrics or tracing spans (check
+// `Connection.OnRequest`).
+func (s *SchemaAPI) SetOnRequest(hook func(info RequestInfo)) {
+	s.conn.OnRequest = hook
+}
+
 func (s *SchemaAPI) post(ctx context.Context, body interface{}) (*Response, error) {
 	bodyBytes, err := interfaceToBytes(body)
 	if err != nil {
 		return nil, err
 	}
-	return s.conn.request(ctx, http.MethodPost, s.Path, bodyBytes)
+	return s.postBytes(ctx, bodyBytes)
+}
+
+// postBytes sends the commands to solr, discarding the cached schema
+// since it is (or may be, in case of an error) changed.
+func (s *SchemaAPI) postBytes(ctx context.Context, body []byte) (*Response, error) {
+	defer s.Invalidate()
+	return s.conn.request(ctx, http.MethodPost, s.Path, ContentTypeJSON, body)
 }
 
 // RetrieveSchema allows you to read how your schema has been defined. The output will
 // include all fields, field types, dynamic rules and copy field rules in json.
 // The schema name and version are also included.
 func (s *SchemaAPI) RetrieveSchema(ctx context.Context) (*Response, error) {
-	return s.conn.request(ctx, http.MethodGet, s.Path, nil)
+	return s.conn.request(ctx, http.MethodGet, s.Path, ContentTypeJSON, nil)
+}
+
+// SetCache enables or disables the cached mode of the schema API. In cached mode the schema is
+// retrieved once and the lookups (e.g. RetrieveField, ListFields & GetUniqueKey) are served
+// from memory, until Invalidate is called or the schema is changed through this API.
+// RetrieveSchema always retrieves the schema from solr. Every lookup returns its own copy
+// of the cached entities, which can be safely modified.
+func (s *SchemaAPI) SetCache(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cached = enabled
+	s.schema = nil
+	s.gen++
+}
+
+// Invalidate discards the cached schema, which is retrieved again on the next lookup.
+// It should be called when the schema is changed by other means than this API.
+func (s *SchemaAPI) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schema = nil
+	s.gen++
+}
+
+// retrieveSchema returns the schema part of the response of RetrieveSchema,
+// which is empty if solr did not return one. In cached mode the schema is
+// only retrieved when not already cached. The schema is cached encoded, so
+// that each call decodes a copy that is not shared with other callers. The
+// lock is not held while retrieving the schema, which is not cached when
+// invalidated in the meantime.
+func (s *SchemaAPI) retrieveSchema(ctx context.Context) (*ResponseSchema, error) {
+	s.mu.Lock()
+	cached, gen, b := s.cached, s.gen, s.schema
+	s.mu.Unlock()
+
+	if b != nil {
+		schema := &ResponseSchema{}
+		err := json.Unmarshal(b, schema)
+		if err != nil {
+			return nil, err
+		}
+		return schema, nil
+	}
+
+	res, err := s.RetrieveSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	schema := res.Schema
+	if schema == nil {
+		schema = &ResponseSchema{}
+	}
+	if !cached {
+		return schema, nil
+	}
+
+	b, err = json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	if s.cached && s.gen == gen {
+		s.schema = b
+	}
+	s.mu.Unlock()
+	return schema, nil
+}
+
+// GetSimilarity returns the global similarity of the schema. Solr's schema API does not
+// provide a command to change the global similarity, which has to be defined in the
+// schema file itself, while similarities per field type can be set through the
+// field type definition. For more info:
+// https://lucene.apache.org/solr/guide/8_5/schema-api.html#show-the-global-similarity
+func (s *SchemaAPI) GetSimilarity(ctx context.Context) (*Similarity, error) {
+	res, err := s.conn.request(ctx, http.MethodGet, s.Path+"/similarity", ContentTypeJSON, nil)
+	if err != nil {
+		return nil, err
+	}
+	return res.Similarity, nil
+}
+
+// SchemaVersion returns the version of the schema, which affects the default values of some
+// field properties (e.g. useDocValuesAsStored is enabled by default since version 1.6).
+// The name of the schema is found in the response of RetrieveSchema. For more info:
+// https://lucene.apache.org/solr/guide/8_5/schema-api.html#show-the-schema-version
+func (s *SchemaAPI) SchemaVersion(ctx context.Context) (float64, error) {
+	schema, err := s.retrieveSchema(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return schema.Version, nil
+}
+
+// GetUniqueKey returns the name of the uniqueKey field of the schema, the field identifying
+// the documents, which is used e.g. by realtime get & deletions by id. Solr's schema API
+// does not provide a command to change it, which has to be done in the schema file
+// itself. For more info:
+// https://lucene.apache.org/solr/guide/8_5/other-schema-elements.html#unique-key
+func (s *SchemaAPI) GetUniqueKey(ctx context.Context) (string, error) {
+	schema, err := s.retrieveSchema(ctx)
+	if err != nil {
+		return "", err
+	}
+	return schema.UniqueKey, nil
 }
 
 // AddFieldType adds a new field type to the schema. For more info:
@@ -211,20 +454,21 @@ func (s *SchemaAPI) DeleteFieldType(ctx context.Context, name string) (*Response
 
 // RetrieveFieldType returns the specified field type.
 func (s *SchemaAPI) RetrieveFieldType(ctx context.Context, name string) (*FieldType, error) {
-	res, err := s.RetrieveSchema(ctx)
+	schema, err := s.retrieveSchema(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	if res.Schema != nil && len(res.Schema.FieldTypes) > 0 {
-		for _, ft := range res.Schema.FieldTypes {
-			if ft.Name == name {
-				return ft, nil
-			}
-		}
-	}
+	return schema.FieldTypeByName(name)
+}
 
-	return nil, ErrFieldTypeNotFound
+// ListFieldTypes returns all the field types of the schema.
+func (s *SchemaAPI) ListFieldTypes(ctx context.Context) ([]*FieldType, error) {
+	schema, err := s.retrieveSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return schema.FieldTypes, nil
 }
 
 // Field methods
@@ -238,6 +482,20 @@ func (s *SchemaAPI) AddField(ctx context.Context, fl *Field) (*Response, error)
 	return s.post(ctx, sb.commands)
 }
 
+// AddFields adds multiple field definitions to your schema in a single request. If any of
+// the fields cannot be added, none of them are and the returned error contains the
+// details for each of the failed additions. If no fields are provided ErrNoFields is returned.
+// For more info:
+// https://lucene.apache.org/solr/guide/8_5/schema-api.html#multiple-commands-in-a-single-post
+func (s *SchemaAPI) AddFields(ctx context.Context, fields []*Field) (*Response, error) {
+	if len(fields) == 0 {
+		return nil, ErrNoFields
+	}
+	sb := newSchemaBuilder()
+	sb.add(SchemaCommandAddField, fields)
+	return s.post(ctx, sb.commands)
+}
+
 // ReplaceField replaces a field’s definition. Note that you must supply the full definition for a
 // field - this command will not partially modify a field’s definition. If the field does not
 // exist in the schema an error is thrown. For more info:
@@ -258,22 +516,37 @@ func (s *SchemaAPI) DeleteField(ctx context.Context, name string) (*Response, er
 	return s.post(ctx, sb.commands)
 }
 
+// DeleteFields removes multiple field definitions from your schema in a single request. If any
+// of the fields cannot be deleted, none of them are and the returned error contains the
+// details for each of the failed deletions. If no names are provided ErrNoFields is returned.
+// For more info:
+// https://lucene.apache.org/solr/guide/8_5/schema-api.html#multiple-commands-in-a-single-post
+func (s *SchemaAPI) DeleteFields(ctx context.Context, names []string) (*Response, error) {
+	if len(names) == 0 {
+		return nil, ErrNoFields
+	}
+	sb := newSchemaBuilder()
+	sb.delMany(SchemaCommandDeleteField, names)
+	return s.post(ctx, sb.commands)
+}
+
 // RetrieveField returns the specified field.
 func (s *SchemaAPI) RetrieveField(ctx context.Context, name string) (*Field, error) {
-	res, err := s.RetrieveSchema(ctx)
+	schema, err := s.retrieveSchema(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	if res.Schema != nil && len(res.Schema.Fields) > 0 {
-		for _, fl := range res.Schema.Fields {
-			if fl.Name == name {
-				return fl, nil
-			}
-		}
-	}
+	return schema.FieldByName(name)
+}
 
-	return nil, ErrFieldNotFound
+// ListFields returns all the fields of the schema.
+func (s *SchemaAPI) ListFields(ctx context.Context) ([]*Field, error) {
+	schema, err := s.retrieveSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return schema.Fields, nil
 }
 
 // Dynamic Field Methods
@@ -308,28 +581,28 @@ func (s *SchemaAPI) DeleteDynamicField(ctx context.Context, name string) (*Respo
 
 // RetrieveDynamicField returns the specified dynamic field.
 func (s *SchemaAPI) RetrieveDynamicField(ctx context.Context, name string) (*DynamicField, error) {
-	res, err := s.RetrieveSchema(ctx)
+	schema, err := s.retrieveSchema(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	if res.Schema != nil && len(res.Schema.DynamicFields) > 0 {
-		for _, df := range res.Schema.DynamicFields {
-			if df.Name == name {
-				return df, nil
-			}
-		}
-	}
+	return schema.DynamicFieldByName(name)
+}
 
-	return nil, ErrDynamicFieldNotFound
+// ListDynamicFields returns all the dynamic field rules of the schema.
+func (s *SchemaAPI) ListDynamicFields(ctx context.Context) ([]*DynamicField, error) {
+	schema, err := s.retrieveSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return schema.DynamicFields, nil
 }
 
 // Copy Field Methods
 
 // AddCopyField adds a new copy field rule to your schema. Source and Destination are required.
-// Destination is always a string so for ease of use, unlike with the json API it is not
-// possible to copy a field to multiple destinations. A different copy field rule must
-// be made for each. For more info:
+// Destination is always a string so for ease of use, check `AddCopyFieldMulti` for copying
+// a field to multiple destinations. For more info:
 // https://lucene.apache.org/solr/guide/8_5/schema-api.html#add-a-new-copy-field-rule
 func (s *SchemaAPI) AddCopyField(ctx context.Context, cf *CopyField) (*Response, error) {
 	sb := newSchemaBuilder()
@@ -337,6 +610,20 @@ func (s *SchemaAPI) AddCopyField(ctx context.Context, cf *CopyField) (*Response,
 	return s.post(ctx, sb.commands)
 }
 
+// AddCopyFieldMulti adds the copy field rules for copying the source field to each of the provided
+// destinations with a single command. A maxChars of 0 sets no limit to the number of characters
+// copied. Solr stores a separate rule for each destination, therefore they are retrieved
+// and deleted one by one. For more info:
+// https://lucene.apache.org/solr/guide/8_5/schema-api.html#add-a-new-copy-field-rule
+func (s *SchemaAPI) AddCopyFieldMulti(ctx context.Context, source string, dests []string, maxChars int) (*Response, error) {
+	if len(dests) == 0 {
+		return nil, ErrNoCopyFieldDest
+	}
+	sb := newSchemaBuilder()
+	sb.add(SchemaCommandAddCopyField, &copyFieldMulti{Source: source, Dest: dests, MaxChars: maxChars})
+	return s.post(ctx, sb.commands)
+}
+
 // DeleteCopyField deletes a copy field rule from your schema. If the copy field rule does not exist in
 // the schema an error is thrown. For more info:
 // https://lucene.apache.org/solr/guide/8_5/schema-api.html#delete-a-copy-field-rule
@@ -348,18 +635,19 @@ func (s *SchemaAPI) DeleteCopyField(ctx context.Context, source, dest string) (*
 
 // RetrieveCopyField returns the specified copy field rule.
 func (s *SchemaAPI) RetrieveCopyField(ctx context.Context, source, dest string) (*CopyField, error) {
-	res, err := s.RetrieveSchema(ctx)
+	schema, err := s.retrieveSchema(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	if res.Schema != nil && len(res.Schema.CopyFields) > 0 {
-		for _, cf := range res.Schema.CopyFields {
-			if cf.Source == source && cf.Dest == dest {
-				return cf, nil
-			}
-		}
-	}
+	return schema.CopyFieldBySourceDest(source, dest)
+}
 
-	return nil, ErrCopyFieldNotFound
+// ListCopyFields returns all the copy field rules of the schema.
+func (s *SchemaAPI) ListCopyFields(ctx context.Context) ([]*CopyField, error) {
+	schema, err := s.retrieveSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return schema.CopyFields, nil
 }
@@ -2,8 +2,15 @@ package solr
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/mecenat/solr/solrtest"
 )
 
 func TestNewSchemaAPIInvalidUrl(t *testing.T) {
@@ -22,3 +29,445 @@ func TestNewSchemaAPIInvalidUrl(t *testing.T) {
 		t.Fatal("shouldn't run without a core defined")
 	}
 }
+
+func TestResponseSchemaLookups(t *testing.T) {
+	s := &ResponseSchema{
+		FieldTypes: []*FieldType{{Name: "string"}},
+		Fields:     []*Field{{Name: "id", Type: "string"}},
+	}
+
+	ft, err := s.FieldTypeByName("string")
+	if err != nil || ft.Name != "string" {
+		t.Fatal("expected to find field type")
+	}
+
+	fl, err := s.FieldByName("id")
+	if err != nil || fl.Name != "id" {
+		t.Fatal("expected to find field")
+	}
+
+	_, err = s.FieldByName("missing")
+	if err != ErrFieldNotFound {
+		t.Fatalf("expected %v but got %v", ErrFieldNotFound, err)
+	}
+
+	var empty *ResponseSchema
+	_, err = empty.DynamicFieldByName("*_s")
+	if err != ErrDynamicFieldNotFound {
+		t.Fatalf("expected %v but got %v", ErrDynamicFieldNotFound, err)
+	}
+}
+
+func TestSimilarityJSON(t *testing.T) {
+	input := `{"class":"solr.BM25SimilarityFactory","k1":1.2}`
+	var sim Similarity
+	err := json.Unmarshal([]byte(input), &sim)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sim.Class != "solr.BM25SimilarityFactory" {
+		t.Fatalf("unexpected class: %s", sim.Class)
+	}
+	if sim.Params["k1"] != 1.2 {
+		t.Fatalf("unexpected params: %v", sim.Params)
+	}
+
+	b, err := json.Marshal(&sim)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(b) != input {
+		t.Fatalf("expected %s but got %s", input, string(b))
+	}
+}
+
+func TestSchemaBuilderDelMany(t *testing.T) {
+	sb := newSchemaBuilder()
+	sb.delMany(SchemaCommandDeleteField, []string{"one", "two"})
+	b, err := interfaceToBytes(sb.commands)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"delete-field":[{"name":"one"},{"name":"two"}]}`
+	if string(b) != expected {
+		t.Fatalf("expected %s but got %s", expected, string(b))
+	}
+}
+
+func TestAddFields(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+
+	sa, err := NewSchemaAPI(context.Background(), srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = sa.AddFields(context.Background(), []*Field{})
+	if err != ErrNoFields {
+		t.Fatalf("expected %v but got %v", ErrNoFields, err)
+	}
+	if len(srv.Requests()) != 0 {
+		t.Fatal("no request should be sent without fields")
+	}
+
+	_, err = sa.AddFields(context.Background(), []*Field{
+		{Name: "name", Type: "text_general"},
+		{Name: "year", Type: "pint"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := srv.LastRequest()
+	if req.Path != "/solr/films/schema" {
+		t.Fatalf("unexpected path: %s", req.Path)
+	}
+	solrtest.AssertJSONBody(t, req, `{"add-field":[{"name":"name","type":"text_general"},{"name":"year","type":"pint"}]}`)
+}
+
+func TestGetSimilarity(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	srv.Handle("/solr/films/schema/similarity", http.StatusOK, `{
+  "responseHeader":{
+    "status":0,
+    "QTime":1},
+  "similarity":{
+    "class":"org.apache.solr.search.similarities.SchemaSimilarityFactory",
+    "defaultSimFromFieldType":"text_bm25"}}`)
+
+	sa, err := NewSchemaAPI(context.Background(), srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sim, err := sa.GetSimilarity(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req := srv.LastRequest()
+	if req.Method != http.MethodGet || req.Path != "/solr/films/schema/similarity" {
+		t.Fatalf("unexpected request: %s %s", req.Method, req.Path)
+	}
+	if sim == nil || sim.Class != "org.apache.solr.search.similarities.SchemaSimilarityFactory" {
+		t.Fatalf("unexpected similarity: %+v", sim)
+	}
+	if sim.Params["defaultSimFromFieldType"] != "text_bm25" {
+		t.Fatalf("unexpected similarity params: %v", sim.Params)
+	}
+}
+
+func TestDeleteFields(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+
+	sa, err := NewSchemaAPI(context.Background(), srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = sa.DeleteFields(context.Background(), nil)
+	if err != ErrNoFields {
+		t.Fatalf("expected %v but got %v", ErrNoFields, err)
+	}
+	if len(srv.Requests()) != 0 {
+		t.Fatal("no request should be sent without fields")
+	}
+
+	_, err = sa.DeleteFields(context.Background(), []string{"name", "year"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	solrtest.AssertJSONBody(t, srv.LastRequest(), `{"delete-field":[{"name":"name"},{"name":"year"}]}`)
+}
+
+func TestValidateDoc(t *testing.T) {
+	required := true
+	s := &ResponseSchema{
+		Fields: []*Field{
+			{Name: "id", Type: "string", FieldDefaultProperties: FieldDefaultProperties{Required: &required}},
+			{Name: "name", Type: "string"},
+			{Name: "seen", Type: "pint", Default: 0, FieldDefaultProperties: FieldDefaultProperties{Required: &required}},
+		},
+		DynamicFields: []*DynamicField{{Name: "*_s", Type: "string"}, {Name: "attr_*", Type: "text_general"}},
+	}
+
+	problems := s.ValidateDoc(map[string]interface{}{"id": "1", "name": "Alien", "genre_s": "horror", "attr_color": "red"})
+	if problems != nil {
+		t.Fatalf("expected no problems but got %v", problems)
+	}
+
+	problems = s.ValidateDoc(map[string]interface{}{"name": "Alien", "year": 1979, "genre": "horror"})
+	if len(problems) != 3 {
+		t.Fatalf("expected 3 problems but got %v", problems)
+	}
+	if problems[0].Field != "genre" || !errors.Is(problems[0], ErrUnknownField) {
+		t.Fatalf("unexpected problem: %v", problems[0])
+	}
+	if problems[1].Field != "id" || !errors.Is(problems[1], ErrRequiredFieldMissing) {
+		t.Fatalf("unexpected problem: %v", problems[1])
+	}
+	if problems[2].Field != "year" || problems[2].Error() != "year: unknown field" {
+		t.Fatalf("unexpected problem: %v", problems[2])
+	}
+}
+
+func TestAddCopyFieldMulti(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+
+	sa, err := NewSchemaAPI(context.Background(), srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = sa.AddCopyFieldMulti(context.Background(), "name", nil, 0)
+	if err != ErrNoCopyFieldDest {
+		t.Fatalf("expected %v but got %v", ErrNoCopyFieldDest, err)
+	}
+
+	_, err = sa.AddCopyFieldMulti(context.Background(), "name", []string{"_text_", "name_exact"}, 256)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reqs := srv.Requests()
+	if len(reqs) != 1 {
+		t.Fatalf("expected a single request but got %d", len(reqs))
+	}
+	solrtest.AssertJSONBody(t, reqs[0], `{"add-copy-field":{"source":"name","dest":["_text_","name_exact"],"maxChars":256}}`)
+}
+
+const testSchemaPayload = `{"responseHeader":{"status":0,"QTime":2},
+	"schema":{"name":"default-config","version":1.6,"uniqueKey":"id",
+		"fieldTypes":[{"name":"pint","class":"solr.IntPointField","docValues":true},
+			{"name":"string","class":"solr.StrField","sortMissingLast":true}],
+		"fields":[{"name":"id","type":"string","required":true},{"name":"year","type":"pint"}],
+		"dynamicFields":[{"name":"*_i","type":"pint"}],
+		"copyFields":[{"source":"id","dest":"_text_"},{"source":"year","dest":"_text_","maxChars":8}]}}`
+
+func TestListSchemaEntities(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	srv.Handle("/solr/films/schema", http.StatusOK, testSchemaPayload)
+
+	sa, err := NewSchemaAPI(context.Background(), srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	fieldTypes, err := sa.ListFieldTypes(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fieldTypes) != 2 || fieldTypes[0].CLass != "solr.IntPointField" {
+		t.Fatalf("unexpected field types: %v", fieldTypes)
+	}
+
+	fields, err := sa.ListFields(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fields) != 2 || fields[1].Name != "year" || fields[1].Type != "pint" {
+		t.Fatalf("unexpected fields: %v", fields)
+	}
+
+	dynamicFields, err := sa.ListDynamicFields(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(dynamicFields) != 1 || dynamicFields[0].Name != "*_i" {
+		t.Fatalf("unexpected dynamic fields: %v", dynamicFields)
+	}
+
+	copyFields, err := sa.ListCopyFields(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(copyFields) != 2 || copyFields[1].MaxChars != 8 {
+		t.Fatalf("unexpected copy fields: %v", copyFields)
+	}
+}
+
+func TestGetUniqueKey(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	srv.Handle("/solr/films/schema", http.StatusOK, testSchemaPayload)
+
+	sa, err := NewSchemaAPI(context.Background(), srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := sa.GetUniqueKey(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key != "id" {
+		t.Fatalf("expected the uniqueKey to be id but got %s", key)
+	}
+}
+
+func TestSchemaVersion(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	srv.Handle("/solr/films/schema", http.StatusOK, testSchemaPayload)
+
+	sa, err := NewSchemaAPI(context.Background(), srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	version, err := sa.SchemaVersion(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if version != 1.6 {
+		t.Fatalf("expected version 1.6 but got %v", version)
+	}
+
+	res, err := sa.RetrieveSchema(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Schema.Name != "default-config" || res.Schema.Version != 1.6 {
+		t.Fatalf("unexpected schema name & version: %s %v", res.Schema.Name, res.Schema.Version)
+	}
+}
+
+func TestSchemaCache(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+	srv.Handle("/solr/films/schema", http.StatusOK, testSchemaPayload)
+
+	sa, err := NewSchemaAPI(context.Background(), srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	lookup := func() {
+		t.Helper()
+		if _, err := sa.RetrieveField(ctx, "id"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := sa.RetrieveFieldType(ctx, "pint"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := sa.RetrieveDynamicField(ctx, "*_i"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := sa.RetrieveCopyField(ctx, "id", "_text_"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	lookup()
+	if n := len(srv.Requests()); n != 4 {
+		t.Fatalf("expected 4 requests without the cache but got %d", n)
+	}
+
+	srv.Reset()
+	srv.Handle("/solr/films/schema", http.StatusOK, testSchemaPayload)
+	sa.SetCache(true)
+	lookup()
+	lookup()
+	if n := len(srv.Requests()); n != 1 {
+		t.Fatalf("expected a single request with the cache but got %d", n)
+	}
+
+	sa.Invalidate()
+	lookup()
+	if n := len(srv.Requests()); n != 2 {
+		t.Fatalf("expected the schema to be retrieved again after invalidating but got %d requests", n)
+	}
+
+	_, err = sa.AddField(ctx, &Field{Name: "name", Type: "string"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lookup()
+	if n := len(srv.Requests()); n != 4 {
+		t.Fatalf("expected the schema to be retrieved again after a change but got %d requests", n)
+	}
+
+	fields, err := sa.ListFields(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fields[0].Name = "changed"
+	fields, err = sa.ListFields(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fields[0].Name == "changed" {
+		t.Fatal("expected the cached schema not to be affected by modifying a lookup's result")
+	}
+}
+
+func TestSchemaConcurrentLookups(t *testing.T) {
+	arrived := make(chan struct{}, 2)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-release
+		w.Write([]byte(testSchemaPayload))
+	}))
+	defer srv.Close()
+
+	sa, err := NewSchemaAPI(context.Background(), srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := sa.RetrieveField(context.Background(), "id")
+			errs <- err
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-arrived:
+		case <-time.After(time.Second):
+			close(release)
+			t.Fatal("expected the lookups to be sent concurrently")
+		}
+	}
+	close(release)
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestFieldTypeSimilarity(t *testing.T) {
+	ft := &FieldType{Name: "text_bm25", CLass: "solr.TextField"}
+	b, err := json.Marshal(ft)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(b), "similarity") {
+		t.Fatalf("similarity should be omitted when not set: %s", b)
+	}
+
+	ft.Similarity = &Similarity{Class: "solr.BM25SimilarityFactory", Params: map[string]interface{}{"k1": 1.1, "b": 0.5}}
+	b, err = json.Marshal(ft)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"name":"text_bm25","class":"solr.TextField","similarity":{"b":0.5,"class":"solr.BM25SimilarityFactory","k1":1.1}}`
+	if string(b) != expected {
+		t.Fatalf("expected %s but got %s", expected, b)
+	}
+
+	var decoded FieldType
+	err = json.Unmarshal(b, &decoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded.Similarity == nil || decoded.Similarity.Class != "solr.BM25SimilarityFactory" || decoded.Similarity.Params["b"] != 0.5 {
+		t.Fatalf("unexpected similarity: %+v", decoded.Similarity)
+	}
+}
@@ -0,0 +1,90 @@
+package solr
+
+import "context"
+
+// SchemaBatch accumulates multiple schema commands in order to send them to solr
+// in a single request using `Commit`, e.g. when bootstrapping a schema. Solr
+// applies the commands in the order they were added to the batch, therefore
+// field types must be added before the fields using them, and a field may
+// be redefined by deleting it before adding it again. If any of the commands
+// fails, none of them are applied. It is obtained from the `NewBatch` method
+// of the SchemaAPI.
+type SchemaBatch struct {
+	api     *SchemaAPI
+	builder *schemaBuilder
+}
+
+// NewBatch returns an empty SchemaBatch that sends its commands using the schema API.
+// For more info:
+// https://lucene.apache.org/solr/guide/8_5/schema-api.html#multiple-commands-in-a-single-post
+func (s *SchemaAPI) NewBatch() *SchemaBatch {
+	return &SchemaBatch{api: s, builder: newSchemaBuilder()}
+}
+
+// AddFieldType adds an add-field-type command to the batch.
+func (b *SchemaBatch) AddFieldType(ft *FieldType) {
+	b.builder.append(SchemaCommandAddFieldType, ft)
+}
+
+// ReplaceFieldType adds a replace-field-type command to the batch.
+func (b *SchemaBatch) ReplaceFieldType(ft *FieldType) {
+	b.builder.append(SchemaCommandReplaceFieldType, ft)
+}
+
+// DeleteFieldType adds a delete-field-type command to the batch.
+func (b *SchemaBatch) DeleteFieldType(name string) {
+	b.builder.append(SchemaCommandDeleteFieldType, map[string]string{"name": name})
+}
+
+// AddField adds an add-field command to the batch.
+func (b *SchemaBatch) AddField(fl *Field) {
+	b.builder.append(SchemaCommandAddField, fl)
+}
+
+// ReplaceField adds a replace-field command to the batch.
+func (b *SchemaBatch) ReplaceField(fl *Field) {
+	b.builder.append(SchemaCommandReplaceField, fl)
+}
+
+// DeleteField adds a delete-field command to the batch.
+func (b *SchemaBatch) DeleteField(name string) {
+	b.builder.append(SchemaCommandDeleteField, map[string]string{"name": name})
+}
+
+// AddDynamicField adds an add-dynamic-field command to the batch.
+func (b *SchemaBatch) AddDynamicField(df *DynamicField) {
+	b.builder.append(SchemaCommandAddDynamicField, df)
+}
+
+// ReplaceDynamicField adds a replace-dynamic-field command to the batch.
+func (b *SchemaBatch) ReplaceDynamicField(df *DynamicField) {
+	b.builder.append(SchemaCommandReplaceDynamicField, df)
+}
+
+// DeleteDynamicField adds a delete-dynamic-field command to the batch.
+func (b *SchemaBatch) DeleteDynamicField(name string) {
+	b.builder.append(SchemaCommandDeleteDynamicField, map[string]string{"name": name})
+}
+
+// AddCopyField adds an add-copy-field command to the batch.
+func (b *SchemaBatch) AddCopyField(cf *CopyField) {
+	b.builder.append(SchemaCommandAddCopyField, cf)
+}
+
+// DeleteCopyField adds a delete-copy-field command to the batch.
+func (b *SchemaBatch) DeleteCopyField(source, dest string) {
+	b.builder.append(SchemaCommandDeleteCopyField, map[string]string{"source": source, "dest": dest})
+}
+
+// Commit sends all the commands of the batch to solr in a single request. If the batch
+// is empty ErrEmptySchemaBatch is returned.
+func (b *SchemaBatch) Commit(ctx context.Context) (*Response, error) {
+	if len(b.builder.actions) == 0 {
+		return nil, ErrEmptySchemaBatch
+	}
+	body, err := b.builder.marshal()
+	if err != nil {
+		return nil, err
+	}
+	return b.api.postBytes(ctx, body)
+}
@@ -0,0 +1,68 @@
+package solr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mecenat/solr/solrtest"
+)
+
+func TestSchemaBatch(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+
+	sa, err := NewSchemaAPI(context.Background(), srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := sa.NewBatch()
+	_, err = b.Commit(context.Background())
+	if err != ErrEmptySchemaBatch {
+		t.Fatalf("expected %v but got %v", ErrEmptySchemaBatch, err)
+	}
+
+	b.AddFieldType(&FieldType{Name: "title", CLass: "solr.TextField"})
+	b.AddField(&Field{Name: "name", Type: "title"})
+	b.AddField(&Field{Name: "year", Type: "pint"})
+	b.AddCopyField(&CopyField{Source: "name", Dest: "_text_"})
+	b.DeleteField("old")
+	_, err = b.Commit(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reqs := srv.Requests()
+	if len(reqs) != 1 {
+		t.Fatalf("expected a single request but got %d", len(reqs))
+	}
+	expected := `{"add-field-type":{"name":"title","class":"solr.TextField"},` +
+		`"add-field":{"name":"name","type":"title"},"add-field":{"name":"year","type":"pint"},` +
+		`"add-copy-field":{"source":"name","dest":"_text_"},` +
+		`"delete-field":{"name":"old"}}`
+	if string(reqs[0].Body) != expected {
+		t.Fatalf("expected body %s but got %s", expected, reqs[0].Body)
+	}
+}
+
+func TestSchemaBatchRedefineField(t *testing.T) {
+	srv := solrtest.NewServer()
+	defer srv.Close()
+
+	sa, err := NewSchemaAPI(context.Background(), srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := sa.NewBatch()
+	b.DeleteField("year")
+	b.AddField(&Field{Name: "year", Type: "pint"})
+	_, err = b.Commit(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"delete-field":{"name":"year"},"add-field":{"name":"year","type":"pint"}}`
+	if body := string(srv.LastRequest().Body); body != expected {
+		t.Fatalf("expected body %s but got %s", expected, body)
+	}
+}
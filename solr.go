@@ -9,14 +9,26 @@ package solr
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
 )
 
 // Client is the interface encompasing all the solr service methods
 type Client interface {
-	// SetBasicAuth sets the authentication credentials if needed.
+	// SetBasicAuth sets the authentication credentials if needed. They can be
+	// overridden for a single request by using a context created with
+	// `WithBasicAuth`.
 	SetBasicAuth(username, password string)
 
+	// SetKeepRaw sets whether the raw body of the responses should be kept in
+	// `Response.Raw`. This allows accessing the parts of a response that are
+	// not yet modeled, without resorting to a raw search.
+	SetKeepRaw(keep bool)
+
+	// SetPingPath sets the path of the request handler used by `Ping`, e.g. when the
+	// health checks are served by a custom handler. Defaults to DefaultPingPath.
+	SetPingPath(path string)
+
 	// Ping checks the connectivity of the solr server. It usually just returns with
 	// Status = OK and a default response header, therefore this function just
 	// returns an error in case there is no response, or an unexpected one.
@@ -29,6 +41,24 @@ type Client interface {
 	// https://lucene.apache.org/solr/guide/8_5/overview-of-searching-in-solr.html
 	Search(ctx context.Context, q *Query) (*Response, error)
 
+	// SearchHandler performs a query to the solr server just like Search, but by using the provided request
+	// handler (e.g. `/browse`) instead of `/select`. This allows targeting custom request handlers that
+	// are configured with their own defaults. For more info:
+	// https://lucene.apache.org/solr/guide/8_5/requesthandlers-and-searchcomponents-in-solrconfig.html
+	SearchHandler(ctx context.Context, handler string, q *Query) (*Response, error)
+
+	// Suggest requests suggestions (e.g. for autocomplete) from the suggester component configured on the given
+	// handler (`/suggest` by default). The suggestions are returned mapped by dictionary in the Suggestions
+	// attribute of the response. For more info:
+	// https://lucene.apache.org/solr/guide/8_5/suggester.html
+	Suggest(ctx context.Context, params *SuggestParams) (*Response, error)
+
+	// Terms requests the indexed terms of the given fields from the terms component configured on the
+	// given handler (`/terms` by default), which is useful e.g. for building dictionaries. The terms
+	// are returned mapped by field in the Terms attribute of the response. For more info:
+	// https://lucene.apache.org/solr/guide/8_5/the-terms-component.html
+	Terms(ctx context.Context, params *TermsParams) (*Response, error)
+
 	// Get performs a realtime get call to the solr server that returns the latest version of the document specified
 	// by its id (uniqueKey field) without the associated cost of reopening a searcher. This is primarily useful
 	// when using Solr as a NoSQL data store and not just a search index. The provided filter should
@@ -42,6 +72,11 @@ type Client interface {
 	// https://lucene.apache.org/solr/guide/8_5/realtime-get.html
 	BatchGet(ctx context.Context, ids []string, filter string) (*Response, error)
 
+	// MaxVersion returns the highest `_version_` value found in the update log of the solr server, by calling
+	// the realtime get handler with the `getVersions` parameter. It can be used to keep track of the latest
+	// changes on the index. The update log must be enabled for this to work.
+	MaxVersion(ctx context.Context) (int64, error)
+
 	// Create adds a single document via JSON to the solr service. It calls the `/update/json/docs` endpoint.
 	// Therefore the provided interface (item) must be a valid JSON object. This method accepts extra
 	// options that are passed to the service as part of the request query. For more info:
@@ -54,18 +89,70 @@ type Client interface {
 	// https://lucene.apache.org/solr/guide/8_5/uploading-data-with-index-handlers.html#adding-multiple-json-documents
 	BatchCreate(ctx context.Context, items interface{}, opts *WriteOptions) (*Response, error)
 
+	// BatchCreateStream adds the documents read from the provided reader via JSON to the solr service. It calls
+	// the `/update/json/docs` endpoint, sending the reader as the body of the request without loading it in
+	// memory, which makes it suitable for large imports. When using a RetryableConnection, a reader that
+	// is not an io.ReadSeeker is sent only once without retries, since retrying would require buffering
+	// it. The reader must contain a JSON array or a sequence of JSON objects (e.g. newline-delimited
+	// JSON). This method accepts extra options that are passed to the service as part of the request
+	// query. For more info:
+	// https://lucene.apache.org/solr/guide/8_5/transforming-and-indexing-custom-json.html
+	BatchCreateStream(ctx context.Context, r io.Reader, opts *WriteOptions) (*Response, error)
+
+	// UploadCSV adds the documents contained in the provided CSV data to the solr service. It calls the `/update`
+	// endpoint with the `application/csv` content type, therefore the first line of the data should contain
+	// the field names. This method accepts extra options that are passed to the service as part of the
+	// request query. For more info:
+	// https://lucene.apache.org/solr/guide/8_5/uploading-data-with-index-handlers.html#csv-formatted-index-updates
+	UploadCSV(ctx context.Context, data []byte, opts *WriteOptions) (*Response, error)
+
+	// UploadXML sends the provided XML update commands to the solr service. It calls the `/update` endpoint with
+	// the `application/xml` content type, therefore the data must be a valid XML update message (e.g. an
+	// `<add>` block). This method accepts extra options that are passed to the service as part of the
+	// request query. For more info:
+	// https://lucene.apache.org/solr/guide/8_5/uploading-data-with-index-handlers.html#xml-formatted-index-updates
+	UploadXML(ctx context.Context, data []byte, opts *WriteOptions) (*Response, error)
+
+	// ExtractAndIndex indexes a rich document (e.g. PDF, Word) by sending it to the `/update/extract` endpoint,
+	// where Solr Cell uses Apache Tika to extract its content. The contentType should match the type of the
+	// provided file. The literals are set as field values on the indexed document (e.g. the id). This
+	// method accepts extra options that are passed to the service as part of the request query.
+	// For more info:
+	// https://lucene.apache.org/solr/guide/8_5/uploading-data-with-solr-cell-using-apache-tika.html
+	ExtractAndIndex(ctx context.Context, file io.Reader, contentType string, literals map[string]string, opts *ExtractOptions) (*Response, error)
+
 	// Update allows for partial updates of documents utilizing the "atomic" and the "in-place" updates approach.
 	// The expected Fields input can be easily created using the provided helpers (check examples). This method
-	// accepts extra options that are passed to the service as part of the request query. For more info:
+	// accepts extra options that are passed to the service as part of the request query. When the RouteField
+	// option is set, ErrMissingRouteField is returned if the update does not include it. When a version is set
+	// on the update and does not match the stored one, the returned error matches ErrVersionConflict. For more info:
 	// https://lucene.apache.org/solr/guide/8_5/updating-parts-of-documents.html#atomic-updates
 	Update(ctx context.Context, item *UpdatedFields, opts *WriteOptions) (*Response, error)
 
+	// Upsert is a helper method that sets the given fields on the document specified by its id (uniqueKey field)
+	// using atomic updates. If the document does not exist it will be created. An "id" key in the fields
+	// is ignored. This method accepts extra options that are passed to the service as part of the
+	// request query. For more info:
+	// https://lucene.apache.org/solr/guide/8_5/updating-parts-of-documents.html#atomic-updates
+	Upsert(ctx context.Context, id string, fields map[string]interface{}, opts *WriteOptions) (*Response, error)
+
 	// DeleteByID sends a JSON update command that deletes the document specified by its id (uniqueKey field).
 	// It calls the `/update` endpoint and sends Solr JSON. This method accepts extra options that are
 	// passed to the service as part of the request query. For more info:
 	// https://lucene.apache.org/solr/guide/8_5/uploading-data-with-index-handlers.html#sending-json-update-commands
 	DeleteByID(ctx context.Context, id string, opts *WriteOptions) (*Response, error)
 
+	// DeleteByIDs sends a JSON update command that deletes all the documents specified by their ids (uniqueKey
+	// field) at once. If the ids are more than the ChunkSize option (1000 by default) they are split in
+	// multiple requests, in which case the response of the last one is returned, containing the deleted
+	// documents of all the requests (check `Response.Affected`). The commit related options are only
+	// sent along with the last request. Chunks are not applied atomically: if a request fails after
+	// others have succeeded, a PartialDeleteError is returned containing the number of ids already
+	// deleted. This method accepts extra options that are passed to the service as part of the
+	// request query. For more info:
+	// https://lucene.apache.org/solr/guide/8_5/uploading-data-with-index-handlers.html#sending-json-update-commands
+	DeleteByIDs(ctx context.Context, ids []string, opts *WriteOptions) (*Response, error)
+
 	// DeleteByID sends a JSON update command that deletes the documents matching the given query. The query format
 	// should follow the syntax of the Q parameter for the Search endpoint. It calls the `/update` endpoint and
 	// sends Solr JSON. This method accepts extra options that are passed to the service as part of the
@@ -97,44 +184,89 @@ type Client interface {
 	// https://lucene.apache.org/solr/guide/8_5/uploading-data-with-index-handlers.html#commit-and-optimize-during-updates
 	Optimize(ctx context.Context, opts *OptimizeOptions) (*Response, error)
 
+	// DataImport sends the given command to the DataImportHandler registered under the provided handler path
+	// (e.g. `/dataimport`). Any extra params (e.g. clean, entity) are passed to the service as part of the
+	// request query. The response contains the status of the import (Status, StatusMessages). For more info:
+	// https://lucene.apache.org/solr/guide/8_5/uploading-structured-data-store-data-with-the-data-import-handler.html#dataimporthandler-commands
+	DataImport(ctx context.Context, handler string, command DataImportCommand, params map[string]string) (*Response, error)
+
 	// CustomUpdate allows the creation of a request to the `/update` endpoint that can include more than one update
 	// command or for those that want a more finegrained request.
 	CustomUpdate(ctx context.Context, item *UpdateBuilder, opts *WriteOptions) (*Response, error)
 }
 
 func read(ctx context.Context, conn connection, url string) (*Response, error) {
-	return conn.request(ctx, http.MethodGet, url, nil)
+	return conn.request(ctx, http.MethodGet, url, ContentTypeJSON, nil)
 }
 
-func create(ctx context.Context, conn connection, url string, item interface{}) (*Response, error) {
+func maxVersion(ctx context.Context, conn connection, url string) (int64, error) {
+	res, err := read(ctx, conn, url)
+	if err != nil {
+		return 0, err
+	}
+	if len(res.Versions) == 0 {
+		return 0, ErrNoVersions
+	}
+
+	// deletions are logged with a negative version
+	var max int64
+	for _, v := range res.Versions {
+		if v < 0 {
+			v = -v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	return max, nil
+}
+
+func create(ctx context.Context, conn connection, url string, item interface{}, opts *WriteOptions) (*Response, error) {
 	bodyBytes, err := interfaceToBytes(item)
 	if err != nil {
 		return nil, err
 	}
 
-	err = isJSON(bodyBytes)
-	if err != nil {
-		return nil, fmt.Errorf("invalid JSON provided: %s", err)
+	if !opts.skipValidation() {
+		err = isJSON(bodyBytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON provided: %s", err)
+		}
 	}
 
-	return conn.request(ctx, http.MethodPost, url, bodyBytes)
+	return conn.request(ctx, http.MethodPost, url, ContentTypeJSON, bodyBytes)
 }
 
-func batchCreate(ctx context.Context, conn connection, url string, items interface{}) (*Response, error) {
+func batchCreate(ctx context.Context, conn connection, url string, items interface{}, opts *WriteOptions) (*Response, error) {
 	bodyBytes, err := interfaceToBytes(items)
 	if err != nil {
 		return nil, err
 	}
 
-	err = isArrayOfJSON(bodyBytes)
-	if err != nil {
-		return nil, fmt.Errorf("invalid Array of JSON provided: %s", err)
+	if !opts.skipValidation() {
+		err = isArrayOfJSON(bodyBytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Array of JSON provided: %s", err)
+		}
 	}
 
-	return conn.request(ctx, http.MethodPost, url, bodyBytes)
+	return conn.request(ctx, http.MethodPost, url, ContentTypeJSON, bodyBytes)
 }
 
-func update(ctx context.Context, conn connection, url string, item *UpdatedFields) (*Response, error) {
+func upload(ctx context.Context, conn connection, url, contentType string, data []byte) (*Response, error) {
+	return conn.request(ctx, http.MethodPost, url, contentType, data)
+}
+
+func batchCreateStream(ctx context.Context, conn connection, url string, r io.Reader) (*Response, error) {
+	return conn.stream(ctx, http.MethodPost, url, ContentTypeJSON, r)
+}
+
+func update(ctx context.Context, conn connection, url string, item *UpdatedFields, opts *WriteOptions) (*Response, error) {
+	err := item.validateRoute(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	ub := NewUpdateBuilder()
 	ub.add(item.fields)
 
@@ -143,7 +275,7 @@ func update(ctx context.Context, conn connection, url string, item *UpdatedField
 		return nil, err
 	}
 
-	return conn.request(ctx, http.MethodPost, url, bodyBytes)
+	return conn.request(ctx, http.MethodPost, url, ContentTypeJSON, bodyBytes)
 }
 
 func delete(ctx context.Context, conn connection, url string, doc Doc) (*Response, error) {
@@ -155,7 +287,47 @@ func delete(ctx context.Context, conn connection, url string, doc Doc) (*Respons
 		return nil, err
 	}
 
-	return conn.request(ctx, http.MethodPost, url, bodyBytes)
+	return conn.request(ctx, http.MethodPost, url, ContentTypeJSON, bodyBytes)
+}
+
+func deleteByIDs(ctx context.Context, conn connection, chunkURL, lastURL string, ids []string, chunkSize int) (*Response, error) {
+	if len(ids) == 0 {
+		return nil, ErrNoIDs
+	}
+
+	var res *Response
+	var deletes DocVersions
+	for start := 0; start < len(ids); start += chunkSize {
+		end := start + chunkSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		ub := NewUpdateBuilder()
+		ub.delete(ids[start:end])
+
+		bodyBytes, err := interfaceToBytes(ub.commands)
+		if err != nil {
+			return nil, err
+		}
+
+		url := chunkURL
+		if end == len(ids) {
+			url = lastURL
+		}
+		res, err = conn.request(ctx, http.MethodPost, url, ContentTypeJSON, bodyBytes)
+		if err != nil {
+			if start > 0 {
+				return res, &PartialDeleteError{Deleted: start, Err: err}
+			}
+			return res, err
+		}
+		deletes = append(deletes, res.Deletes...)
+	}
+	if deletes != nil {
+		res.Deletes = deletes
+	}
+	return res, nil
 }
 
 func commit(ctx context.Context, conn connection, url string, opts *CommitOptions) (*Response, error) {
@@ -167,7 +339,7 @@ func commit(ctx context.Context, conn connection, url string, opts *CommitOption
 		return nil, err
 	}
 
-	return conn.request(ctx, http.MethodPost, url, bodyBytes)
+	return conn.request(ctx, http.MethodPost, url, ContentTypeJSON, bodyBytes)
 }
 
 func optimize(ctx context.Context, conn connection, url string, opts *OptimizeOptions) (*Response, error) {
@@ -179,7 +351,7 @@ func optimize(ctx context.Context, conn connection, url string, opts *OptimizeOp
 		return nil, err
 	}
 
-	return conn.request(ctx, http.MethodPost, url, bodyBytes)
+	return conn.request(ctx, http.MethodPost, url, ContentTypeJSON, bodyBytes)
 }
 
 func rollback(ctx context.Context, conn connection, url string) (*Response, error) {
@@ -191,16 +363,14 @@ func rollback(ctx context.Context, conn connection, url string) (*Response, erro
 		return nil, err
 	}
 
-	return conn.request(ctx, http.MethodPost, url, bodyBytes)
+	return conn.request(ctx, http.MethodPost, url, ContentTypeJSON, bodyBytes)
 }
 
 func customUpdate(ctx context.Context, conn connection, url string, item *UpdateBuilder) (*Response, error) {
-	item.prepare()
-
-	bodyBytes, err := interfaceToBytes(item.commands)
+	bodyBytes, err := item.marshal()
 	if err != nil {
 		return nil, err
 	}
 
-	return conn.request(ctx, http.MethodPost, url, bodyBytes)
+	return conn.request(ctx, http.MethodPost, url, ContentTypeJSON, bodyBytes)
 }
@@ -0,0 +1,144 @@
+// Package solrtest provides a fake solr server to be used when testing code that
+// depends on the solr client, without the need of a running solr instance. The
+// server records every request it receives and replies with canned responses.
+package solrtest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// DefaultResponse is returned for every path with no registered response.
+const DefaultResponse = `{"responseHeader":{"status":0,"QTime":0}}`
+
+// Request is a request recorded by the fake server.
+type Request struct {
+	Method      string
+	Path        string
+	Query       url.Values
+	ContentType string
+	Body        []byte
+}
+
+type cannedResponse struct {
+	status int
+	body   string
+}
+
+// Server is a fake solr server. Its URL should be used as the host when creating
+// a connection, e.g. `solr.NewConnection(srv.URL, "core", srv.Client())`.
+type Server struct {
+	*httptest.Server
+	mu        sync.Mutex
+	requests  []*Request
+	responses map[string]*cannedResponse
+}
+
+// NewServer starts and returns a new fake solr server. It should be closed
+// when the test finishes.
+func NewServer() *Server {
+	s := &Server{responses: make(map[string]*cannedResponse)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, &Request{
+		Method:      r.Method,
+		Path:        r.URL.Path,
+		Query:       r.URL.Query(),
+		ContentType: r.Header.Get("Content-Type"),
+		Body:        body,
+	})
+	res, ok := s.responses[r.URL.Path]
+	s.mu.Unlock()
+
+	if !ok {
+		res = &cannedResponse{status: http.StatusOK, body: DefaultResponse}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(res.status)
+	io.WriteString(w, res.body)
+}
+
+// Handle registers the response (status code and JSON body) returned for
+// requests to the given path, e.g. `/solr/core/select`.
+func (s *Server) Handle(path string, status int, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[path] = &cannedResponse{status: status, body: body}
+}
+
+// Requests returns all the requests recorded so far.
+func (s *Server) Requests() []*Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requests := make([]*Request, len(s.requests))
+	copy(requests, s.requests)
+	return requests
+}
+
+// LastRequest returns the last recorded request or nil if there is none.
+func (s *Server) LastRequest() *Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.requests) == 0 {
+		return nil
+	}
+	return s.requests[len(s.requests)-1]
+}
+
+// Reset removes all the recorded requests and registered responses.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+	s.responses = make(map[string]*cannedResponse)
+}
+
+// AssertParam fails the test if the given query parameter of the request
+// does not have the expected value.
+func AssertParam(t testing.TB, r *Request, key, expected string) {
+	t.Helper()
+	if r == nil {
+		t.Fatal("no request recorded")
+	}
+	actual := r.Query.Get(key)
+	if actual != expected {
+		t.Fatalf("expected param %s to be %q but got %q", key, expected, actual)
+	}
+}
+
+// AssertJSONBody fails the test if the body of the request is not JSON
+// equivalent to the expected one.
+func AssertJSONBody(t testing.TB, r *Request, expected string) {
+	t.Helper()
+	if r == nil {
+		t.Fatal("no request recorded")
+	}
+	var actualJSON, expectedJSON interface{}
+	err := json.Unmarshal(r.Body, &actualJSON)
+	if err != nil {
+		t.Fatalf("request body is not valid JSON: %v", err)
+	}
+	err = json.Unmarshal([]byte(expected), &expectedJSON)
+	if err != nil {
+		t.Fatalf("expected body is not valid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(actualJSON, expectedJSON) {
+		t.Fatalf("expected body %s but got %s", expected, string(r.Body))
+	}
+}
@@ -0,0 +1,68 @@
+package solrtest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/mecenat/solr"
+)
+
+func TestServerSearch(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.Handle("/solr/films/select", http.StatusOK, `{"response":{"numFound":1,"start":0,"docs":[{"id":"1"}]}}`)
+
+	conn, err := solr.NewConnection(srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	slr, err := solr.NewSingleClient(conn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	q := solr.NewQuery(nil)
+	q.SetQuery("name:alien")
+	res, err := slr.Search(context.Background(), q)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Data.NumFound != 1 {
+		t.Fatalf("expected 1 document but got %d", res.Data.NumFound)
+	}
+	AssertParam(t, srv.LastRequest(), "q", "name:alien")
+}
+
+func TestServerUpdate(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	conn, err := solr.NewConnection(srv.URL, "films", srv.Client())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	slr, err := solr.NewSingleClient(conn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = slr.DeleteByID(context.Background(), "1", &solr.WriteOptions{Commit: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req := srv.LastRequest()
+	if req.Method != http.MethodPost || req.Path != "/solr/films/update" {
+		t.Fatalf("unexpected request: %s %s", req.Method, req.Path)
+	}
+	AssertParam(t, req, "commit", "true")
+	AssertJSONBody(t, req, `{"delete":{"id":"1"}}`)
+
+	if len(srv.Requests()) != 1 {
+		t.Fatalf("expected 1 recorded request but got %d", len(srv.Requests()))
+	}
+	srv.Reset()
+	if srv.LastRequest() != nil {
+		t.Fatal("requests should be removed after reset")
+	}
+}
@@ -0,0 +1,70 @@
+package solr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SortDirection is used to restrict the available directions of a sort clause
+type SortDirection string
+
+func (d SortDirection) String() string {
+	return string(d)
+}
+
+// Available sort directions
+const (
+	SortAsc  SortDirection = "asc"
+	SortDesc SortDirection = "desc"
+)
+
+// SortBuilder helps composing the sort param of a query out of multiple clauses,
+// which are applied in the order they were added. Its methods can be chained:
+//
+//	NewSortBuilder().Desc("score").Asc("year")
+//
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/common-query-parameters.html#sort-parameter
+type SortBuilder struct {
+	clauses []string
+}
+
+// NewSortBuilder returns an empty SortBuilder
+func NewSortBuilder() *SortBuilder {
+	return &SortBuilder{}
+}
+
+// Asc adds a clause sorting on the given field in ascending order
+func (b *SortBuilder) Asc(field string) *SortBuilder {
+	return b.add(field, SortAsc)
+}
+
+// Desc adds a clause sorting on the given field in descending order
+func (b *SortBuilder) Desc(field string) *SortBuilder {
+	return b.add(field, SortDesc)
+}
+
+// ByFunction adds a clause sorting on the result of the given function query,
+// e.g. `div(popularity,price)`, in the given direction.
+func (b *SortBuilder) ByFunction(function string, dir SortDirection) *SortBuilder {
+	return b.add(function, dir)
+}
+
+func (b *SortBuilder) add(value string, dir SortDirection) *SortBuilder {
+	b.clauses = append(b.clauses, fmt.Sprintf("%s %s", value, dir))
+	return b
+}
+
+// String returns the sort clauses joined as expected by the sort param
+func (b *SortBuilder) String() string {
+	return strings.Join(b.clauses, ",")
+}
+
+// SetSortBuilder sets the way the results are sorted using the clauses of the
+// given SortBuilder. Nothing is set if the builder is nil or empty.
+func (q *Query) SetSortBuilder(b *SortBuilder) {
+	if b == nil || len(b.clauses) == 0 {
+		return
+	}
+	q.SetSort(b.String())
+}
@@ -0,0 +1,18 @@
+package solr
+
+import "testing"
+
+func TestSetSortBuilder(t *testing.T) {
+	q := NewQuery(nil)
+	q.SetSortBuilder(nil)
+	if q.params.Get("sort") != "" {
+		t.Fatal("expected no sort param for a nil builder")
+	}
+
+	b := NewSortBuilder().Desc("score").Asc("year").ByFunction("div(popularity,price)", SortDesc)
+	q.SetSortBuilder(b)
+	expected := "score desc,year asc,div(popularity,price) desc"
+	if q.params.Get("sort") != expected {
+		t.Fatalf("expected %s but got %s", expected, q.params.Get("sort"))
+	}
+}
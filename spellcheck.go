@@ -0,0 +1,169 @@
+package solr
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// Spellcheck Options
+const (
+	OptionSpellcheck           = "spellcheck"
+	OptionSpellcheckQ          = "spellcheck.q"
+	OptionSpellcheckCount      = "spellcheck.count"
+	OptionSpellcheckCollate    = "spellcheck.collate"
+	OptionSpellcheckDictionary = "spellcheck.dictionary"
+)
+
+// SpellcheckParams contains the available parameters for the spellcheck
+// component. None of the params is required, when Q is empty solr
+// uses the main query instead.
+type SpellcheckParams struct {
+	Q          string
+	Count      int
+	Collate    bool
+	Dictionary string
+}
+
+// Spellcheck enables the spellcheck component which provides query suggestions
+// based on similar terms (check `Response.Spellcheck`). The component must
+// be configured on the request handler of the search.
+// More info:
+// https://lucene.apache.org/solr/guide/8_5/spell-checking.html
+func (q *Query) Spellcheck(params *SpellcheckParams) {
+	q.params.Set(OptionSpellcheck, "true")
+	if params == nil {
+		return
+	}
+	if params.Q != "" {
+		q.params.Set(OptionSpellcheckQ, params.Q)
+	}
+	if params.Count > 0 {
+		q.params.Set(OptionSpellcheckCount, strconv.Itoa(params.Count))
+	}
+	if params.Collate {
+		q.params.Set(OptionSpellcheckCollate, "true")
+	}
+	if params.Dictionary != "" {
+		q.params.Set(OptionSpellcheckDictionary, params.Dictionary)
+	}
+}
+
+// Spellcheck is populated whenever the spellcheck component is enabled. Solr
+// returns suggestions and collations as arrays that alternate between a
+// name and a value, therefore a custom unmarshaler is used to parse
+// them in a more Go-friendly way.
+type Spellcheck struct {
+	Suggestions      []*SpellcheckSuggestion
+	CorrectlySpelled bool
+	Collated         []*SpellcheckCollation
+}
+
+// SpellcheckSuggestion contains the suggestions for a single misspelled term.
+type SpellcheckSuggestion struct {
+	Term        string
+	NumFound    int      `json:"numFound"`
+	StartOffset int      `json:"startOffset"`
+	EndOffset   int      `json:"endOffset"`
+	Words       []string `json:"-"`
+}
+
+// SpellcheckCollation contains a collation, a query rewritten with the best
+// suggestions. Hits is populated only when extended collate results are
+// requested.
+type SpellcheckCollation struct {
+	Query string `json:"collationQuery"`
+	Hits  int    `json:"hits"`
+}
+
+// Collations returns the collated queries suggested by solr.
+func (s *Spellcheck) Collations() []string {
+	if s == nil {
+		return nil
+	}
+	var collations []string
+	for _, c := range s.Collated {
+		collations = append(collations, c.Query)
+	}
+	return collations
+}
+
+// UnmarshalJSON implements the unmarshaler interface.
+func (s *Spellcheck) UnmarshalJSON(b []byte) error {
+	var temp struct {
+		Suggestions      []json.RawMessage `json:"suggestions"`
+		CorrectlySpelled bool              `json:"correctlySpelled"`
+		Collations       []json.RawMessage `json:"collations"`
+	}
+	err := json.Unmarshal(b, &temp)
+	if err != nil {
+		return err
+	}
+	s.CorrectlySpelled = temp.CorrectlySpelled
+
+	for i := 0; i+1 < len(temp.Suggestions); i += 2 {
+		var term string
+		err = json.Unmarshal(temp.Suggestions[i], &term)
+		if err != nil {
+			return err
+		}
+		var sug SpellcheckSuggestion
+		err = json.Unmarshal(temp.Suggestions[i+1], &sug)
+		if err != nil {
+			return err
+		}
+		sug.Term = term
+		words, err := parseSpellcheckWords(temp.Suggestions[i+1])
+		if err != nil {
+			return err
+		}
+		sug.Words = words
+		s.Suggestions = append(s.Suggestions, &sug)
+	}
+
+	for i := 0; i+1 < len(temp.Collations); i += 2 {
+		var col SpellcheckCollation
+		var query string
+		if json.Unmarshal(temp.Collations[i+1], &query) == nil {
+			col.Query = query
+		} else {
+			err = json.Unmarshal(temp.Collations[i+1], &col)
+			if err != nil {
+				return err
+			}
+		}
+		s.Collated = append(s.Collated, &col)
+	}
+
+	return nil
+}
+
+// parseSpellcheckWords returns the suggested words, which are either plain
+// strings or objects containing the word and its frequency when extended
+// results are requested.
+func parseSpellcheckWords(b []byte) ([]string, error) {
+	var temp struct {
+		Suggestion []json.RawMessage `json:"suggestion"`
+	}
+	err := json.Unmarshal(b, &temp)
+	if err != nil {
+		return nil, err
+	}
+
+	var words []string
+	for _, raw := range temp.Suggestion {
+		var word string
+		if json.Unmarshal(raw, &word) == nil {
+			words = append(words, word)
+			continue
+		}
+		var extended struct {
+			Word string `json:"word"`
+		}
+		err = json.Unmarshal(raw, &extended)
+		if err != nil {
+			return nil, err
+		}
+		words = append(words, extended.Word)
+	}
+	return words, nil
+}
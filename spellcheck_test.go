@@ -0,0 +1,63 @@
+package solr
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSpellcheck(t *testing.T) {
+	q := NewQuery(nil)
+	q.Spellcheck(&SpellcheckParams{Q: "delll", Count: 5, Collate: true, Dictionary: "default"})
+	if q.params.Get("spellcheck") != "true" {
+		t.Fatal("spellcheck param not registered")
+	}
+	if q.params.Get("spellcheck.q") != "delll" {
+		t.Fatal("spellcheck.q param not registered")
+	}
+	if q.params.Get("spellcheck.count") != "5" {
+		t.Fatal("spellcheck.count param not registered")
+	}
+	if q.params.Get("spellcheck.collate") != "true" {
+		t.Fatal("spellcheck.collate param not registered")
+	}
+	if q.params.Get("spellcheck.dictionary") != "default" {
+		t.Fatal("spellcheck.dictionary param not registered")
+	}
+}
+
+func TestSpellcheckUnmarshal(t *testing.T) {
+	input := `{"spellcheck":{
+		"suggestions":["delll",{"numFound":2,"startOffset":0,"endOffset":5,"suggestion":["dell",{"word":"bell","freq":2}]}],
+		"correctlySpelled":false,
+		"collations":["collation","dell","collation",{"collationQuery":"bell","hits":3}]}}`
+	var res Response
+	err := json.Unmarshal([]byte(input), &res)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(res.Spellcheck.Suggestions) != 1 {
+		t.Fatalf("expected 1 suggestion but got %d", len(res.Spellcheck.Suggestions))
+	}
+	sug := res.Spellcheck.Suggestions[0]
+	if sug.Term != "delll" || sug.NumFound != 2 || len(sug.Words) != 2 || sug.Words[1] != "bell" {
+		t.Fatalf("unexpected suggestion: %+v", sug)
+	}
+
+	collations := res.Spellcheck.Collations()
+	if len(collations) != 2 || collations[0] != "dell" || collations[1] != "bell" {
+		t.Fatalf("unexpected collations: %v", collations)
+	}
+}
+
+func TestSpellcheckUnmarshalEmpty(t *testing.T) {
+	input := `{"spellcheck":{"suggestions":[],"collations":[]}}`
+	var res Response
+	err := json.Unmarshal([]byte(input), &res)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(res.Spellcheck.Suggestions) != 0 || len(res.Spellcheck.Collations()) != 0 {
+		t.Fatal("expected no suggestions")
+	}
+}
@@ -0,0 +1,83 @@
+package solr
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+)
+
+// Suggester Options
+const (
+	OptionSuggest           = "suggest"
+	OptionSuggestQ          = "suggest.q"
+	OptionSuggestDictionary = "suggest.dictionary"
+	OptionSuggestCount      = "suggest.count"
+	DefaultSuggestHandler   = "/suggest"
+)
+
+// SuggestParams contains the available parameters for a request to the suggester.
+// Q is required. Handler defaults to `/suggest` when empty. When no dictionary
+// is given the ones configured on the handler are used.
+type SuggestParams struct {
+	Handler    string
+	Q          string
+	Dictionary []string
+	Count      int
+}
+
+func (p *SuggestParams) handlerPath() string {
+	if p.Handler == "" {
+		return DefaultSuggestHandler
+	}
+	return formatHandlerPath(p.Handler)
+}
+
+func (p *SuggestParams) format() (url.Values, error) {
+	if p == nil || p.Q == "" {
+		return nil, ErrParamsRequired
+	}
+	vals := make(url.Values)
+	vals.Set(OptionSuggest, "true")
+	vals.Set(OptionSuggestQ, p.Q)
+	for _, d := range p.Dictionary {
+		vals.Add(OptionSuggestDictionary, d)
+	}
+	if p.Count > 0 {
+		vals.Set(OptionSuggestCount, strconv.Itoa(p.Count))
+	}
+	vals.Set(OptionWT, ReturnTypeJSON)
+	return vals, nil
+}
+
+// Suggestion is a single suggestion returned by the suggester.
+type Suggestion struct {
+	Term    string `json:"term"`
+	Weight  int64  `json:"weight"`
+	Payload string `json:"payload"`
+}
+
+// Suggestions contains the suggestions returned by each suggester dictionary,
+// mapped by the dictionary name. Solr nests the suggestions under the
+// query as well, which is omitted here since it's the one requested.
+type Suggestions map[string][]Suggestion
+
+// UnmarshalJSON implements the unmarshaler interface.
+func (s *Suggestions) UnmarshalJSON(b []byte) error {
+	var temp map[string]map[string]struct {
+		NumFound    int          `json:"numFound"`
+		Suggestions []Suggestion `json:"suggestions"`
+	}
+	err := json.Unmarshal(b, &temp)
+	if err != nil {
+		return err
+	}
+
+	m := make(Suggestions)
+	for dictionary, queries := range temp {
+		for _, res := range queries {
+			m[dictionary] = append(m[dictionary], res.Suggestions...)
+		}
+	}
+	*s = m
+	return nil
+}
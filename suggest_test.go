@@ -0,0 +1,40 @@
+package solr
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSuggestParams(t *testing.T) {
+	var p *SuggestParams
+	_, err := p.format()
+	if err == nil {
+		t.Fatal("shouldn't run without params")
+	}
+
+	p = &SuggestParams{Q: "elec", Dictionary: []string{"mySuggester"}, Count: 5}
+	vals, err := p.format()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "suggest=true&suggest.count=5&suggest.dictionary=mySuggester&suggest.q=elec&wt=json"
+	if vals.Encode() != expected {
+		t.Fatalf("expected %s but got %s", expected, vals.Encode())
+	}
+	if p.handlerPath() != DefaultSuggestHandler {
+		t.Fatalf("expected default handler but got %s", p.handlerPath())
+	}
+}
+
+func TestSuggestionsUnmarshal(t *testing.T) {
+	input := `{"suggest":{"mySuggester":{"elec":{"numFound":1,"suggestions":[{"term":"electronics","weight":2199,"payload":""}]}}}}`
+	var res Response
+	err := json.Unmarshal([]byte(input), &res)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sugs := res.Suggestions["mySuggester"]
+	if len(sugs) != 1 || sugs[0].Term != "electronics" || sugs[0].Weight != 2199 {
+		t.Fatalf("unexpected suggestions: %+v", sugs)
+	}
+}
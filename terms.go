@@ -0,0 +1,98 @@
+package solr
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+)
+
+// Terms component Options
+const (
+	OptionTerms         = "terms"
+	OptionTermsFields   = "terms.fl"
+	OptionTermsPrefix   = "terms.prefix"
+	OptionTermsRegex    = "terms.regex"
+	OptionTermsLimit    = "terms.limit"
+	OptionTermsSort     = "terms.sort"
+	DefaultTermsHandler = "/terms"
+	TermsSortCount      = "count"
+	TermsSortIndex      = "index"
+)
+
+// TermsParams contains the available parameters for a request to the terms component.
+// Fields is required. Handler defaults to `/terms` when empty. Sort may be either
+// `count` (default) or `index`.
+type TermsParams struct {
+	Handler string
+	Fields  []string
+	Prefix  string
+	Regex   string
+	Limit   int
+	Sort    string
+}
+
+func (p *TermsParams) handlerPath() string {
+	if p.Handler == "" {
+		return DefaultTermsHandler
+	}
+	return formatHandlerPath(p.Handler)
+}
+
+func (p *TermsParams) format() (url.Values, error) {
+	if p == nil || len(p.Fields) == 0 {
+		return nil, ErrParamsRequired
+	}
+	vals := make(url.Values)
+	vals.Set(OptionTerms, "true")
+	for _, f := range p.Fields {
+		vals.Add(OptionTermsFields, f)
+	}
+	if p.Prefix != "" {
+		vals.Set(OptionTermsPrefix, p.Prefix)
+	}
+	if p.Regex != "" {
+		vals.Set(OptionTermsRegex, p.Regex)
+	}
+	if p.Limit != 0 {
+		vals.Set(OptionTermsLimit, strconv.Itoa(p.Limit))
+	}
+	if p.Sort != "" {
+		vals.Set(OptionTermsSort, p.Sort)
+	}
+	vals.Set(OptionWT, ReturnTypeJSON)
+	return vals, nil
+}
+
+// TermCount is a single term of a field along with the number of documents containing it.
+type TermCount struct {
+	Term  string
+	Count int
+}
+
+// Terms contains the terms returned by the terms component, mapped by field.
+type Terms map[string][]TermCount
+
+// UnmarshalJSON implements the unmarshaler interface. Solr returns the terms of
+// each field in an array that alternates between the term and its count.
+func (t *Terms) UnmarshalJSON(b []byte) error {
+	var temp map[string][]interface{}
+	err := json.Unmarshal(b, &temp)
+	if err != nil {
+		return err
+	}
+
+	m := make(Terms)
+	for field, v := range temp {
+		counts := make([]TermCount, 0, len(v)/2)
+		for i := 0; i+1 < len(v); i += 2 {
+			s, ok := v[i].(string)
+			n, ok2 := toFloat64(v[i+1])
+			if ok && ok2 {
+				counts = append(counts, TermCount{Term: s, Count: int(n)})
+			}
+		}
+		m[field] = counts
+	}
+	*t = m
+	return nil
+}
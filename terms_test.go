@@ -0,0 +1,44 @@
+package solr
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTermsParams(t *testing.T) {
+	var p *TermsParams
+	_, err := p.format()
+	if err == nil {
+		t.Fatal("shouldn't run without params")
+	}
+
+	p = &TermsParams{Fields: []string{"name", "genre"}, Prefix: "al", Regex: "al.*", Limit: 5, Sort: TermsSortIndex}
+	vals, err := p.format()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "terms=true&terms.fl=name&terms.fl=genre&terms.limit=5&terms.prefix=al&terms.regex=al.%2A&terms.sort=index&wt=json"
+	if vals.Encode() != expected {
+		t.Fatalf("expected %s but got %s", expected, vals.Encode())
+	}
+	if p.handlerPath() != DefaultTermsHandler {
+		t.Fatalf("expected default handler but got %s", p.handlerPath())
+	}
+}
+
+func TestTermsUnmarshal(t *testing.T) {
+	input := `{"terms":{"genre":["horror",6,"comedy",4],"name":[]}}`
+	var res Response
+	err := json.Unmarshal([]byte(input), &res)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	genre := res.Terms["genre"]
+	if len(genre) != 2 || genre[0].Term != "horror" || genre[0].Count != 6 || genre[1].Term != "comedy" || genre[1].Count != 4 {
+		t.Fatalf("unexpected terms: %v", genre)
+	}
+	if len(res.Terms["name"]) != 0 {
+		t.Fatalf("expected no terms but got %v", res.Terms["name"])
+	}
+}
@@ -1,5 +1,12 @@
 package solr
 
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+)
+
 // Constants for different actions and commands used
 // for the `/update` endpoint
 const (
@@ -9,6 +16,7 @@ const (
 	ActionRemove              = "remove"
 	ActionRemoveRegex         = "removeregex"
 	ActionIncrement           = "inc"
+	FieldVersion              = "_version_"
 	CommandAdd        Command = "add"
 	CommandDelete     Command = "delete"
 	CommandCommit     Command = "commit"
@@ -16,6 +24,30 @@ const (
 	CommandOptimize   Command = "optimize"
 )
 
+// ErrMissingRouteField is returned when an atomic update does not include the
+// field used for routing the documents to shards.
+var ErrMissingRouteField = errors.New("the update does not include the route field")
+
+// ErrNoIDs is returned when deleting by ids without providing any
+var ErrNoIDs = errors.New("no ids provided")
+
+// PartialDeleteError is returned when deleting by ids in multiple chunks and one
+// of the chunks after the first fails. Deleted is the number of ids that were
+// sent in the already applied chunks, while Err is the error of the failed one.
+type PartialDeleteError struct {
+	Deleted int
+	Err     error
+}
+
+func (e *PartialDeleteError) Error() string {
+	return fmt.Sprintf("delete failed after %d ids: %s", e.Deleted, e.Err)
+}
+
+// Unwrap returns the underlying error, allowing the use of `errors.Is` and `errors.As`.
+func (e *PartialDeleteError) Unwrap() error {
+	return e.Err
+}
+
 // CommitOptions are the available options to a commit update command.
 type CommitOptions struct {
 	DoNotWaitSearcher bool
@@ -39,17 +71,29 @@ func (c Command) String() string {
 // UpdateBuilder is a helper struct that provides methods to
 // easily populate the body of a custom `/update` request
 type UpdateBuilder struct {
+	ordered   bool
 	additions []interface{}
 	deletions []interface{}
+	actions   []*updateAction
 	commands  map[Command]interface{}
 }
 
+// updateAction is a single command that is sent separately from the
+// grouped ones, keeping its position in the body.
+type updateAction struct {
+	command Command
+	value   interface{}
+}
+
 // NewUpdateBuilder returns an initialized UpdateBuilder, a helper struct that provides methods to
 // easily populate a custom request to the `/update` endpoint of the solr server, that can
 // contain more than one action. Multiple additions or deletion are grouped in an array
 // when sent to solr instead of a map (as seen in solr docs) for obvious reasons.
-// Therefore actual action hierarchy CANNOT be achieved! It's usage is suggested
-// for any cases that the methods provided by the Client does not cover.
+// Therefore actual action hierarchy CANNOT be achieved (check `NewOrderedUpdateBuilder`)!
+// Additions with options (check `AddWithCommitWithin`) are sent after the grouped
+// additions & deletions, while any other commands (e.g. commit) are sent last.
+// It's usage is suggested for any cases that the methods provided by the Client
+// does not cover.
 // More info:
 // https://lucene.apache.org/solr/guide/8_5/uploading-data-with-index-handlers.html#sending-json-update-commands
 func NewUpdateBuilder() *UpdateBuilder {
@@ -57,6 +101,16 @@ func NewUpdateBuilder() *UpdateBuilder {
 	return &UpdateBuilder{commands: commands}
 }
 
+// NewOrderedUpdateBuilder returns an initialized UpdateBuilder that preserves the order in
+// which the additions & deletions are inserted, e.g. for an add -> delete -> add sequence.
+// Each of them is sent as a separate command, repeating the command name in the
+// body as seen in solr docs, which solr processes in order.
+func NewOrderedUpdateBuilder() *UpdateBuilder {
+	b := NewUpdateBuilder()
+	b.ordered = true
+	return b
+}
+
 func (b *UpdateBuilder) add(item map[string]interface{}) {
 	b.commands[CommandAdd] = formatDocEntry(item)
 }
@@ -74,23 +128,103 @@ func (b *UpdateBuilder) prepare() {
 	}
 }
 
+func (b *UpdateBuilder) addAction(command Command, value interface{}) {
+	b.actions = append(b.actions, &updateAction{command: command, value: value})
+}
+
+// marshal returns the body of the request. The grouped additions & deletions are
+// written first, followed by the separate actions in the order they were
+// inserted and finally the rest of the commands (e.g. commit), so that they
+// apply to all of the above. Since the actions may repeat a command name,
+// the body is written by hand.
+func (b *UpdateBuilder) marshal() ([]byte, error) {
+	b.prepare()
+	if len(b.actions) == 0 {
+		return interfaceToBytes(b.commands)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	write := func(command Command, v interface{}) error {
+		value, err := interfaceToBytes(v)
+		if err != nil {
+			return err
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(`"` + command.String() + `":`)
+		buf.Write(value)
+		return nil
+	}
+
+	grouped := []Command{CommandAdd, CommandDelete}
+	for _, command := range grouped {
+		if v, ok := b.commands[command]; ok {
+			if err := write(command, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, action := range b.actions {
+		if err := write(action.command, action.value); err != nil {
+			return nil, err
+		}
+	}
+
+	var rest []string
+	for command := range b.commands {
+		if command != CommandAdd && command != CommandDelete {
+			rest = append(rest, command.String())
+		}
+	}
+	sort.Strings(rest)
+	for _, command := range rest {
+		if err := write(Command(command), b.commands[Command(command)]); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
 // Add inserts an add command block to the body. The provided input
 // must be valid JSON. For atomic or in-place updates it is
 // recommended to use the `Update` method that is provided
 // by the Client interface.
 func (b *UpdateBuilder) Add(item interface{}) {
+	if b.ordered {
+		b.addAction(CommandAdd, map[string]interface{}{"doc": item})
+		return
+	}
 	b.additions = append(b.additions, item)
 }
 
+// AddWithCommitWithin inserts an add command block to the body, for which solr
+// automatically commits the document within the given time (in milliseconds).
+// This allows mixing documents with different commit requirements in a
+// single request. The provided input must be valid JSON.
+func (b *UpdateBuilder) AddWithCommitWithin(item interface{}, ms int64) {
+	b.addAction(CommandAdd, map[string]interface{}{"doc": item, OptionCommitWithin: ms})
+}
+
 // DeleteByID inserts a delete command block to the body. It should
 // contain a document identifying the id (uniqueKey field)
 func (b *UpdateBuilder) DeleteByID(id string) {
+	if b.ordered {
+		b.addAction(CommandDelete, formatDeleteByID(id))
+		return
+	}
 	b.deletions = append(b.deletions, formatDeleteByID(id))
 }
 
 // DeleteByQuery inserts a delete command block to the body. It should
 // contain a document identifying a query to properly work.
 func (b *UpdateBuilder) DeleteByQuery(query string) {
+	if b.ordered {
+		b.addAction(CommandDelete, formatDeleteByQuery(query))
+		return
+	}
 	b.deletions = append(b.deletions, formatDeleteByQuery(query))
 }
 
@@ -166,6 +300,42 @@ func NewUpdateDocument(id string) *UpdatedFields {
 	return &UpdatedFields{fields: fields}
 }
 
+func newUpsertDocument(id string, fields map[string]interface{}) *UpdatedFields {
+	doc := NewUpdateDocument(id)
+	for key, val := range fields {
+		if key == "id" {
+			continue
+		}
+		if key == FieldVersion {
+			doc.fields[key] = val
+			continue
+		}
+		doc.Set(key, val)
+	}
+	return doc
+}
+
+func (f *UpdatedFields) validateRoute(opts *WriteOptions) error {
+	if opts == nil || opts.RouteField == "" {
+		return nil
+	}
+	val, ok := f.fields[opts.RouteField]
+	if !ok || val == nil || val == "" {
+		return ErrMissingRouteField
+	}
+	return nil
+}
+
+// SetVersion sets the `_version_` of the document, which enables optimistic concurrency
+// control. The update is only applied if the version matches the one of the stored
+// document, otherwise an error matching ErrVersionConflict is returned. A
+// version of 1 requires the document to exist (regardless of its version),
+// while a negative version requires it to not exist. More info:
+// https://lucene.apache.org/solr/guide/8_5/updating-parts-of-documents.html#optimistic-concurrency
+func (f *UpdatedFields) SetVersion(v int64) {
+	f.fields[FieldVersion] = v
+}
+
 // Set replaces or sets the field value(s) with the specified values(s).
 // Takes as input a key which is the field name and a val which is
 // the provided value(s) to set.
@@ -207,3 +377,10 @@ func (f *UpdatedFields) RemoveRegex(key string, val interface{}) {
 func (f *UpdatedFields) IncrementBy(key string, val int) {
 	f.fields[key] = map[string]interface{}{ActionIncrement: val}
 }
+
+// IncrementByFloat increments a numeric value by a specific floating point
+// amount. Takes as input a key which is the field name and a val which
+// is a float64 signifying the amount to increment by.
+func (f *UpdatedFields) IncrementByFloat(key string, val float64) {
+	f.fields[key] = map[string]interface{}{ActionIncrement: val}
+}
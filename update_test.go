@@ -1,6 +1,9 @@
 package solr
 
 import (
+	"errors"
+	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -153,3 +156,149 @@ func TestUpdateIncrementBy(t *testing.T) {
 		t.Fatalf("expected property to be %d but instead got %d", input, actual.(int))
 	}
 }
+
+func TestUpdateIncrementByFloat(t *testing.T) {
+	upd := NewUpdateDocument("test")
+	input := 2.5
+	upd.IncrementByFloat("field", input)
+	innerMap := upd.fields["field"].(map[string]interface{})
+	actual, ok := innerMap[ActionIncrement]
+	if !ok {
+		t.Fatal("Increment property not found!")
+	}
+	if actual.(float64) != input {
+		t.Fatalf("expected property to be %f but instead got %f", input, actual.(float64))
+	}
+
+	b, err := interfaceToBytes(upd.fields["field"])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"inc":2.5}`
+	if string(b) != expected {
+		t.Fatalf("expected %s but got %s", expected, string(b))
+	}
+}
+
+func TestNewUpsertDocument(t *testing.T) {
+	upd := newUpsertDocument("test", map[string]interface{}{"id": "other", "field": "value"})
+	if upd.fields["id"] != "test" {
+		t.Fatalf("expected id to be %s but got %v", "test", upd.fields["id"])
+	}
+	innerMap := upd.fields["field"].(map[string]interface{})
+	actual, ok := innerMap[ActionSet]
+	if !ok {
+		t.Fatal("Set property not found!")
+	}
+	if actual.(string) != "value" {
+		t.Fatalf("expected property to be %s but instead got %s", "value", actual.(string))
+	}
+}
+
+func TestUpdateValidateRoute(t *testing.T) {
+	upd := NewUpdateDocument("test")
+	upd.Set("field", "value")
+	if upd.validateRoute(nil) != nil {
+		t.Fatal("route should not be validated without a route field")
+	}
+	opts := &WriteOptions{RouteField: "tenant"}
+	if upd.validateRoute(opts) != ErrMissingRouteField {
+		t.Fatal("expected missing route field error")
+	}
+	upd.Set("tenant", "acme")
+	if upd.validateRoute(opts) != nil {
+		t.Fatal("route field should be found")
+	}
+}
+
+func TestSetVersion(t *testing.T) {
+	doc := NewUpdateDocument("1")
+	doc.SetVersion(1681234567890123456)
+	doc.Set("name", "Alien")
+	if doc.fields["_version_"] != int64(1681234567890123456) {
+		t.Fatalf("unexpected version: %v", doc.fields["_version_"])
+	}
+
+	upsert := newUpsertDocument("1", map[string]interface{}{"_version_": -1, "name": "Alien"})
+	if upsert.fields["_version_"] != -1 {
+		t.Fatalf("expected the version not to be wrapped in a set but got %v", upsert.fields["_version_"])
+	}
+}
+
+func TestVersionConflictError(t *testing.T) {
+	body := `{"responseHeader":{"status":409,"QTime":1},"error":{"metadata":["error-class","org.apache.solr.common.SolrException"],"msg":"version conflict for 1 expected=1 actual=1681234567890123456","code":409}}`
+	_, err := decodeResponse(http.StatusConflict, strings.NewReader(body), false, false)
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected %v but got %v", ErrVersionConflict, err)
+	}
+	if errors.Is(err, ErrUnknownField) {
+		t.Fatal("did not expect the error to match ErrUnknownField")
+	}
+
+	_, err = decodeResponse(http.StatusConflict, strings.NewReader("Conflict"), false, false)
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("expected %v but got %v", ErrVersionConflict, err)
+	}
+}
+
+func TestAddWithCommitWithin(t *testing.T) {
+	u := NewUpdateBuilder()
+	u.Add(map[string]interface{}{"id": "1"})
+	u.AddWithCommitWithin(map[string]interface{}{"id": "2"}, 1000)
+
+	add, ok := u.actions[0].value.(map[string]interface{})
+	if len(u.actions) != 1 || !ok || add["commitWithin"] != int64(1000) {
+		t.Fatalf("unexpected actions: %v", u.actions)
+	}
+
+	b, err := u.marshal()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"add":[{"id":"1"}],"add":{"commitWithin":1000,"doc":{"id":"2"}}}`
+	if string(b) != expected {
+		t.Fatalf("expected %s but got %s", expected, string(b))
+	}
+
+	u = NewUpdateBuilder()
+	u.AddWithCommitWithin(map[string]interface{}{"id": "3"}, 500)
+	b, err = u.marshal()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = `{"add":{"commitWithin":500,"doc":{"id":"3"}}}`
+	if string(b) != expected {
+		t.Fatalf("expected %s but got %s", expected, string(b))
+	}
+
+	u = NewUpdateBuilder()
+	u.DeleteByQuery("*:*")
+	u.AddWithCommitWithin(map[string]interface{}{"id": "4"}, 500)
+	u.commit(nil)
+	b, err = u.marshal()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = `{"delete":[{"query":"*:*"}],"add":{"commitWithin":500,"doc":{"id":"4"}},"commit":{}}`
+	if string(b) != expected {
+		t.Fatalf("expected %s but got %s", expected, string(b))
+	}
+}
+
+func TestOrderedUpdateBuilder(t *testing.T) {
+	u := NewOrderedUpdateBuilder()
+	u.Add(map[string]interface{}{"id": "1"})
+	u.DeleteByID("2")
+	u.Add(map[string]interface{}{"id": "2"})
+	u.DeleteByQuery("genre:horror")
+	u.commit(nil)
+
+	b, err := u.marshal()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"add":{"doc":{"id":"1"}},"delete":{"id":"2"},"add":{"doc":{"id":"2"}},"delete":{"query":"genre:horror"},"commit":{}}`
+	if string(b) != expected {
+		t.Fatalf("expected %s but got %s", expected, string(b))
+	}
+}
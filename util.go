@@ -1,15 +1,29 @@
 package solr
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// DefaultPingPath is the path of the request handler used by the clients for pinging solr
+const DefaultPingPath = "/admin/ping"
+
 // ErrInvalidConfig is returned when the hostname or corename are empty
 var ErrInvalidConfig = errors.New("invalid configuration: no host or core provided")
 
+// ErrNoVersions is returned when the update log of the solr server contains no versions
+var ErrNoVersions = errors.New("no versions found in the update log")
+
+// ErrNoReplicas is returned when creating a client without any replica connections
+var ErrNoReplicas = errors.New("invalid configuration: no replicas provided")
+
+var errNotJSONArray = errors.New("input is not a JSON array")
+
 func formatBasePath(host, core string) string {
 	if strings.HasSuffix(host, "/solr") {
 		return fmt.Sprintf("%s/%s", host, core)
@@ -17,6 +31,28 @@ func formatBasePath(host, core string) string {
 	return fmt.Sprintf("%s/solr/%s", host, core)
 }
 
+func formatURL(basePath, path, query string) string {
+	if query != "" {
+		return basePath + path + "?" + query
+	}
+	return basePath + path
+}
+
+// pingPath returns the formatted ping path, or DefaultPingPath when empty.
+func pingPath(path string) string {
+	if path == "" {
+		return DefaultPingPath
+	}
+	return formatHandlerPath(path)
+}
+
+func formatHandlerPath(handler string) string {
+	if strings.HasPrefix(handler, "/") {
+		return handler
+	}
+	return "/" + handler
+}
+
 func formatDocEntry(doc Doc) map[string]interface{} {
 	return map[string]interface{}{"doc": doc}
 }
@@ -35,8 +71,42 @@ func isJSON(input []byte) error {
 }
 
 func isArrayOfJSON(input []byte) error {
-	var js []*map[string]interface{}
-	return json.Unmarshal(input, &js)
+	trimmed := bytes.TrimLeft(input, " \t\r\n")
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		return errNotJSONArray
+	}
+	var js []json.RawMessage
+	return json.Unmarshal(trimmed, &js)
+}
+
+// toFloat64 converts a decoded JSON value to a float64, tolerating the
+// different ways solr (and the decoder) may represent a number.
+func toFloat64(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// toTime converts a decoded JSON value to a time, as solr returns dates as
+// strings in the ISO 8601 format.
+func toTime(v interface{}) (time.Time, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
 }
 
 func interfaceToBytes(a interface{}) ([]byte, error) {
@@ -51,3 +121,37 @@ func interfaceToBytes(a interface{}) ([]byte, error) {
 func BoostField(field string, boost float64) string {
 	return fmt.Sprintf("%s^%f", field, boost)
 }
+
+// termsSeparators are the candidate separators for a terms query, the first
+// one not contained in any of the values is used.
+var termsSeparators = []string{",", "|", ";", "~", "^", "\t"}
+
+// ErrNoTermsSeparator is returned when formatting a terms query whose values
+// contain every one of the candidate separators.
+var ErrNoTermsSeparator = errors.New("no separator found that is not contained in the terms values")
+
+// FormatTermsQuery is a helper function to properly format a terms query parser
+// clause for the given field and values. The default `,` separator is
+// switched to another one (`|`, `;`, `~`, `^` or a tab) when any of the
+// values contains it. ErrNoTermsSeparator is returned when the values
+// contain all of them.
+func FormatTermsQuery(field string, values []string) (string, error) {
+	for _, sep := range termsSeparators {
+		if !containsSeparator(values, sep) {
+			if sep == "," {
+				return fmt.Sprintf("{!terms f=%s}%s", field, strings.Join(values, sep)), nil
+			}
+			return fmt.Sprintf("{!terms f=%s separator=\"%s\"}%s", field, sep, strings.Join(values, sep)), nil
+		}
+	}
+	return "", ErrNoTermsSeparator
+}
+
+func containsSeparator(values []string, sep string) bool {
+	for _, v := range values {
+		if strings.Contains(v, sep) {
+			return true
+		}
+	}
+	return false
+}
@@ -41,3 +41,45 @@ func TestIsArrayOfJSONValid(t *testing.T) {
 		t.Fatal("got error while input is valid")
 	}
 }
+
+func TestIsArrayOfJSONMixedElements(t *testing.T) {
+	input := ` [{"key": "value"}, "value", 1]`
+	err := isArrayOfJSON([]byte(input))
+	if err != nil {
+		t.Fatal("got error while input is valid")
+	}
+}
+
+func TestFormatHandlerPath(t *testing.T) {
+	if formatHandlerPath("browse") != "/browse" {
+		t.Fatal("handler path should be prefixed with a slash")
+	}
+	if formatHandlerPath("/browse") != "/browse" {
+		t.Fatal("handler path should not be prefixed twice")
+	}
+}
+
+func TestFormatTermsQuery(t *testing.T) {
+	actual, err := FormatTermsQuery("tags", []string{"one", "two"})
+	if err != nil {
+		t.Fatalf("expected no error but got %s", err)
+	}
+	expected := "{!terms f=tags}one,two"
+	if actual != expected {
+		t.Fatalf("expected %s but got %s", expected, actual)
+	}
+
+	actual, err = FormatTermsQuery("tags", []string{"one,two", "three"})
+	if err != nil {
+		t.Fatalf("expected no error but got %s", err)
+	}
+	expected = `{!terms f=tags separator="|"}one,two|three`
+	if actual != expected {
+		t.Fatalf("expected %s but got %s", expected, actual)
+	}
+
+	_, err = FormatTermsQuery("tags", []string{"a,b|c;d", "e~f^g\th"})
+	if err != ErrNoTermsSeparator {
+		t.Fatalf("expected %v but got %v", ErrNoTermsSeparator, err)
+	}
+}